@@ -1,75 +1,152 @@
 package flagvars
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/hex"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"strings"
+	"sync"
 )
 
 // bytesHexValue adapts []byte for use as a flag. Value of flag is HEX encoded
-type bytesHexValue []byte
+type bytesHexValue struct {
+	dst    *[]byte
+	source string
+}
 
 // String implements flag.Value.String.
 func (bytesHex bytesHexValue) String() string {
-	return fmt.Sprintf("%X", []byte(bytesHex))
+	if bytesHex.source != "" {
+		return bytesHex.source
+	}
+	if bytesHex.dst == nil {
+		return ""
+	}
+	return fmt.Sprintf("%X", *bytesHex.dst)
 }
 
 // Set implements flag.Value.Set.
 func (bytesHex *bytesHexValue) Set(value string) error {
-	data, err := hex.DecodeString(strings.TrimSpace(value))
+	raw, source, err := resolvePrivate(value, 0)
+	if err != nil {
+		return err
+	}
+
+	data, err := hex.DecodeString(strings.TrimSpace(string(raw)))
 	if err != nil {
 		return err
 	}
 
-	*bytesHex = data
+	*bytesHex.dst = data
+	bytesHex.source = source
 
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*bytesHexValue) Type() string {
-	return "bytesHex"
+	return TypeBytesHex
+}
+
+// Get implements flag.Getter. It returns a []byte.
+func (bytesHex bytesHexValue) Get() interface{} {
+	return *bytesHex.dst
+}
+
+// sourceRef implements reloadable.
+func (bytesHex *bytesHexValue) sourceRef() string {
+	return bytesHex.source
 }
 
 // BytesHex creates and returns a new flag.Value compliant hex bytes parser.
 func BytesHex(p *[]byte, value []byte) flag.Value {
 	*p = value
-	return (*bytesHexValue)(p)
+	return &bytesHexValue{dst: p}
 }
 
 // bytesBase64Value adapts []byte for use as a flag. Value of flag is Base64 encoded
-type bytesBase64Value []byte
+type bytesBase64Value struct {
+	dst    *[]byte
+	source string
+}
 
 // String implements flag.Value.String.
 func (bytesBase64 bytesBase64Value) String() string {
-	return base64.StdEncoding.EncodeToString([]byte(bytesBase64))
+	if bytesBase64.source != "" {
+		return bytesBase64.source
+	}
+	if bytesBase64.dst == nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(*bytesBase64.dst)
 }
 
 // Set implements flag.Value.Set.
 func (bytesBase64 *bytesBase64Value) Set(value string) error {
-	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	raw, source, err := resolvePrivate(value, 0)
+	if err != nil {
+		return err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
 	if err != nil {
 		return err
 	}
 
-	*bytesBase64 = data
+	*bytesBase64.dst = data
+	bytesBase64.source = source
 
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*bytesBase64Value) Type() string {
-	return "bytesBase64"
+	return TypeBytesBase64
+}
+
+// Get implements flag.Getter. It returns a []byte.
+func (bytesBase64 bytesBase64Value) Get() interface{} {
+	return *bytesBase64.dst
+}
+
+// sourceRef implements reloadable.
+func (bytesBase64 *bytesBase64Value) sourceRef() string {
+	return bytesBase64.source
 }
 
 // BytesBase64 creates and returns a new flag.Value compliant base64 bytes
 // parser.
 func BytesBase64(p *[]byte, value []byte) flag.Value {
 	*p = value
-	return (*bytesBase64Value)(p)
+	return &bytesBase64Value{dst: p}
+}
+
+// BytesFileOptions tunes how the content of files is read.
+type BytesFileOptions struct {
+	// MaxSize, if positive, is the maximum size of the content in bytes.
+	// Larger files are refused without being read past the limit.
+	MaxSize int64
+	// TrimNewline removes a single trailing newline, \n or \r\n, which
+	// editors add to key files.
+	TrimNewline bool
+}
+
+// readFile returns the content value refers to, a file path unless it has a
+// source prefix, according to opts.
+func readFile(value string, opts BytesFileOptions) ([]byte, error) {
+	if !hasSourcePrefix(value) {
+		value = "file:" + value
+	}
+	data, _, err := resolvePrivate(value, opts.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	if opts.TrimNewline && bytes.HasSuffix(data, []byte("\n")) {
+		data = bytes.TrimSuffix(data[:len(data)-1], []byte("\r"))
+	}
+	return data, nil
 }
 
 // bytesFileValue adapts []byte for use as a flag. Value of flag is the binary
@@ -77,6 +154,10 @@ func BytesBase64(p *[]byte, value []byte) flag.Value {
 type bytesFileValue struct {
 	filename string
 	data     *[]byte
+	opts     BytesFileOptions
+	// defaultErr is the failure to read the default file, reported by
+	// Validate unless another value is set.
+	defaultErr error
 }
 
 // String implements flag.Value.String.
@@ -84,28 +165,235 @@ func (bf bytesFileValue) String() string {
 	return bf.filename
 }
 
-// Set implements flag.Value.Set.
+// Set implements flag.Value.Set. Values without a source prefix are taken
+// as a file path.
 func (bf *bytesFileValue) Set(value string) error {
 	bf.filename = value
 
-	data, err := ioutil.ReadFile(bf.filename)
+	data, err := readFile(value, bf.opts)
 	if err != nil {
 		return err
 	}
 
 	*bf.data = data
+	bf.defaultErr = nil
 
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*bytesFileValue) Type() string {
-	return "bytesFile"
+	return TypeBytesFile
+}
+
+// Get implements flag.Getter. It returns a []byte.
+func (bf bytesFileValue) Get() interface{} {
+	return *bf.data
+}
+
+// sourceRef implements reloadable.
+func (bf *bytesFileValue) sourceRef() string {
+	return bf.filename
 }
 
-// BytesFile creates and returns a new flag.Value compliant base64 bytes parser.
+// defaultError implements defaultErrorer.
+func (bf *bytesFileValue) defaultError() error {
+	return bf.defaultErr
+}
+
+// BytesFile creates and returns a new flag.Value compliant file bytes
+// parser. A default file which cannot be read is reported by Validate, and
+// so by Parse, unless the flag is set. Files are checked against
+// KeyFilePermissions.
 func BytesFile(p *[]byte, value string) flag.Value {
-	bf := &bytesFileValue{data: p}
-	_ = bf.Set(value)
+	return BytesFileWithOptions(p, value, BytesFileOptions{})
+}
+
+// BytesFileWithOptions is like BytesFile but reads files according to opts.
+func BytesFileWithOptions(p *[]byte, value string, opts BytesFileOptions) flag.Value {
+	bf := &bytesFileValue{data: p, opts: opts}
+	if value != "" {
+		if err := bf.Set(value); err != nil {
+			bf.defaultErr = err
+		}
+	}
 	return bf
 }
+
+// LazyFile holds the content of a file which is read when first accessed
+// rather than when its flag is set, so that unused flags cost nothing and
+// files may appear after flags are parsed. It is safe for concurrent use.
+type LazyFile struct {
+	mu     sync.Mutex
+	value  string
+	opts   BytesFileOptions
+	loaded bool
+	data   []byte
+}
+
+// Bytes returns the content of the file, reading it on the first successful
+// call after its flag was set. Failures are not kept, so that the file is
+// read again on the next call.
+func (f *LazyFile) Bytes() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.value == "" || f.loaded {
+		return f.data, nil
+	}
+	data, err := readFile(f.value, f.opts)
+	if err != nil {
+		return nil, err
+	}
+	f.data, f.loaded = data, true
+	return f.data, nil
+}
+
+// set makes f refer to value, dropping the content read so far.
+func (f *LazyFile) set(value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.value, f.loaded, f.data = value, false, nil
+}
+
+// lazyFileValue adapts LazyFile for use as a flag. Value of flag is a file
+// path or a source.
+type lazyFileValue struct {
+	dst *LazyFile
+}
+
+// String implements flag.Value.String.
+func (v lazyFileValue) String() string {
+	if v.dst == nil {
+		return ""
+	}
+	v.dst.mu.Lock()
+	defer v.dst.mu.Unlock()
+	return v.dst.value
+}
+
+// Set implements flag.Value.Set. Nothing is read until LazyFile.Bytes is
+// called.
+func (v *lazyFileValue) Set(value string) error {
+	v.dst.set(value)
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (*lazyFileValue) Type() string {
+	return TypeBytesFileLazy
+}
+
+// Get implements flag.Getter. It returns a *LazyFile.
+func (v lazyFileValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *lazyFileValue) sourceRef() string {
+	return v.String()
+}
+
+// concurrentReload implements concurrentReloadable.
+func (*lazyFileValue) concurrentReload() {}
+
+// BytesFileLazy creates and returns a new flag.Value compliant file bytes
+// parser deferring reads to LazyFile.Bytes, which reads files according to
+// opts.
+func BytesFileLazy(f *LazyFile, value string, opts BytesFileOptions) flag.Value {
+	f.opts = opts
+	f.set(value)
+	return &lazyFileValue{dst: f}
+}
+
+// bytesValue adapts []byte for use as a flag. Value of flag is prefixed by
+// its encoding or source, falling back to a default encoding.
+type bytesValue struct {
+	dst *[]byte
+	enc Encoding
+	// prefix is the encoding prefix of the last value set, if any.
+	prefix string
+	// value is the value the last value was set through, nil for raw: ones.
+	value flag.Value
+}
+
+// String implements flag.Value.String. Values are rendered with the prefix
+// and encoding they were set with so that they round-trip.
+func (v bytesValue) String() string {
+	if v.dst == nil {
+		return ""
+	}
+	switch {
+	case v.prefix == "raw:":
+		return v.prefix + string(*v.dst)
+	case v.value != nil:
+		return v.prefix + v.value.String()
+	}
+	return v.enc.EncodeToString(*v.dst)
+}
+
+// Set implements flag.Value.Set. It accepts hex:, 0x, base64:, b64url: and
+// raw: prefixed values, any source understood by Resolve, whose content is
+// taken as is, and values encoded with the default encoding otherwise.
+func (v *bytesValue) Set(value string) error {
+	var (
+		prefix = ""
+		inner  flag.Value
+	)
+	switch {
+	case strings.HasPrefix(value, "raw:"):
+		*v.dst = []byte(strings.TrimPrefix(value, "raw:"))
+		v.prefix, v.value = "raw:", nil
+		return nil
+	case strings.HasPrefix(value, "hex:"):
+		prefix, inner = "hex:", &bytesHexValue{dst: v.dst}
+	case strings.HasPrefix(value, "0x"), strings.HasPrefix(value, "0X"):
+		prefix, inner = value[:2], &bytesHexValue{dst: v.dst}
+	case strings.HasPrefix(value, "base64:"):
+		prefix, inner = "base64:", &bytesBase64Value{dst: v.dst}
+	case strings.HasPrefix(value, "b64url:"):
+		// Padding is optional, as in JWTs.
+		value = strings.TrimRight(value, "=")
+		prefix, inner = "b64url:", &bytesEncodedValue{dst: v.dst, enc: base64.RawURLEncoding}
+	case hasSourcePrefix(value) && !strings.HasPrefix(value, "literal:"):
+		inner = &bytesFileValue{data: v.dst}
+	default:
+		inner = &bytesEncodedValue{dst: v.dst, enc: v.enc}
+	}
+
+	if err := inner.Set(strings.TrimPrefix(value, prefix)); err != nil {
+		return err
+	}
+	v.prefix, v.value = prefix, inner
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (*bytesValue) Type() string {
+	return TypeBytes
+}
+
+// Get implements flag.Getter. It returns a []byte.
+func (v bytesValue) Get() interface{} {
+	return *v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *bytesValue) sourceRef() string {
+	if r, ok := v.value.(reloadable); ok && r.sourceRef() != "" {
+		return v.String()
+	}
+	return ""
+}
+
+// Bytes creates and returns a new flag.Value compliant bytes parser
+// accepting values prefixed by their encoding, hex:, 0x, base64: or b64url:,
+// raw: for the bytes of the value itself, or a source such as file: or env:
+// whose content is taken as is. Values without a prefix are decoded with
+// enc, Hex if nil.
+func Bytes(p *[]byte, value []byte, enc Encoding) flag.Value {
+	if enc == nil {
+		enc = Hex
+	}
+	*p = value
+	return &bytesValue{dst: p, enc: enc}
+}
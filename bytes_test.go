@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -163,3 +164,177 @@ func TestBytesFileVar(t *testing.T) {
 	fs := flag.NewFlagSet("test", flag.ExitOnError)
 	fs.Var(BytesFile(&b, ""), "file", "file data")
 }
+
+func TestBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	if err := ioutil.WriteFile(path, []byte("\x00raw\n"), 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	t.Setenv("TEST_BYTES", "from env")
+
+	testCases := []struct {
+		enc      Encoding
+		input    string
+		success  bool
+		expected []byte
+		str      string
+	}{
+		// Positive cases
+		{nil, "0102", true, []byte{1, 2}, "0102"},
+		{nil, "hex:0a0b", true, []byte{10, 11}, "hex:0A0B"},
+		{nil, "0x0a0b", true, []byte{10, 11}, "0x0A0B"},
+		{nil, "base64:AQI=", true, []byte{1, 2}, "base64:AQI="},
+		{nil, "b64url:-_8", true, []byte{0xfb, 0xff}, "b64url:-_8"},
+		{nil, "b64url:-_8=", true, []byte{0xfb, 0xff}, "b64url:-_8"},
+		{nil, "raw:hello", true, []byte("hello"), "raw:hello"},
+		{nil, "file:" + path, true, []byte("\x00raw\n"), "file:" + path},
+		{nil, "env:TEST_BYTES", true, []byte("from env"), "env:TEST_BYTES"},
+		{Base58, "5Q", true, []byte{0xff}, "5Q"},
+		{Base58, "hex:ff", true, []byte{0xff}, "hex:FF"},
+
+		// Negative cases
+		{nil, "zz", false, nil, ""},
+		{nil, "hex:env:TEST_BYTES", false, nil, ""},
+		{nil, "base64:!", false, nil, ""},
+		{nil, "file:" + path + ".missing", false, nil, ""},
+	}
+
+	for _, tc := range testCases {
+		var data []byte
+		v := Bytes(&data, nil, tc.enc)
+		err := v.Set(tc.input)
+		if err != nil && tc.success {
+			t.Errorf("expected success, got %q", err)
+			continue
+		} else if err == nil && !tc.success {
+			t.Errorf("expected failure while processing %q", tc.input)
+			continue
+		} else if !tc.success {
+			continue
+		}
+		if !bytes.Equal(data, tc.expected) || v.String() != tc.str {
+			t.Errorf("got: %X, %q, expected %X, %q", data, v.String(), tc.expected, tc.str)
+			continue
+		}
+
+		// String round-trips.
+		var again []byte
+		if err := Bytes(&again, nil, tc.enc).Set(v.String()); err != nil || !bytes.Equal(again, data) {
+			t.Errorf("got: %X, %v, expected %q to round-trip", again, err, v.String())
+		}
+	}
+}
+
+func TestBytesVar(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	BytesFlag(fs, nil, "key", []byte{1, 2}, "key")
+	if f := fs.Lookup("key"); f.DefValue != "0102" {
+		t.Fatalf("got: %q, expected %q", f.DefValue, "0102")
+	}
+}
+
+func TestBytesFileDefault(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key")
+	if err := ioutil.WriteFile(path, []byte("key"), 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	var b []byte
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(BytesFile(&b, filepath.Join(dir, "missing")), "key", "key file")
+	if err := Parse(fs, nil); err == nil {
+		t.Fatalf("expected failure with missing default file")
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(BytesFile(&b, filepath.Join(dir, "missing")), "key", "key file")
+	if err := Parse(fs, []string{"-key=" + path}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if string(b) != "key" {
+		t.Fatalf("got: %q, expected %q", b, "key")
+	}
+}
+
+func TestBytesFileWithOptions(t *testing.T) {
+	dir := t.TempDir()
+	testCases := []struct {
+		content  string
+		opts     BytesFileOptions
+		success  bool
+		expected string
+	}{
+		// Positive cases
+		{"key\n", BytesFileOptions{}, true, "key\n"},
+		{"key\n", BytesFileOptions{TrimNewline: true}, true, "key"},
+		{"key\r\n", BytesFileOptions{TrimNewline: true}, true, "key"},
+		{"key\n\n", BytesFileOptions{TrimNewline: true}, true, "key\n"},
+		{"key\r", BytesFileOptions{TrimNewline: true}, true, "key\r"},
+		{"key\r\r\n", BytesFileOptions{TrimNewline: true}, true, "key\r"},
+		{"key", BytesFileOptions{MaxSize: 3}, true, "key"},
+
+		// Negative cases
+		{"keys", BytesFileOptions{MaxSize: 3}, false, ""},
+	}
+	for i, tc := range testCases {
+		path := filepath.Join(dir, fmt.Sprint(i))
+		if err := ioutil.WriteFile(path, []byte(tc.content), 0600); err != nil {
+			t.Fatalf("expected success, got %q", err)
+		}
+		for _, value := range []string{path, "@" + path} {
+			var b []byte
+			err := BytesFileWithOptions(&b, "", tc.opts).Set(value)
+			if tc.success != (err == nil) {
+				t.Fatalf("unexpected result for %q, err: %v", tc.content, err)
+			}
+			if string(b) != tc.expected {
+				t.Fatalf("got: %q, expected %q", b, tc.expected)
+			}
+		}
+	}
+}
+
+func TestBytesFileLazy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+
+	var f LazyFile
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(BytesFileLazy(&f, "", BytesFileOptions{TrimNewline: true}), "key", "key file")
+	if err := fs.Parse([]string{"-key=" + path}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if _, err := f.Bytes(); err == nil {
+		t.Fatalf("expected failure with missing file")
+	}
+	if err := ioutil.WriteFile(path, []byte("key\n"), 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	b, err := f.Bytes()
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if string(b) != "key" {
+		t.Fatalf("got: %q, expected %q", b, "key")
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if b, err := f.Bytes(); err != nil || string(b) != "key" {
+		t.Fatalf("expected cached content, got %q, %v", b, err)
+	}
+	if err := fs.Set("key", path); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if _, err := f.Bytes(); err == nil {
+		t.Fatalf("expected failure with missing file")
+	}
+}
+
+func TestBytesFileLazyVar(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	f := BytesFileLazyFlag(fs, "key", "", BytesFileOptions{MaxSize: 1 << 20}, "key file")
+	if b, err := f.Bytes(); err != nil || b != nil {
+		t.Fatalf("expected no content, got %q, %v", b, err)
+	}
+}
@@ -0,0 +1,349 @@
+package flagvars
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CA is an in-process certificate authority issuing short-lived leaf
+// certificates signed by a CA certificate and its private key.
+type CA struct {
+	// LeafLifetime is the validity period of issued certificates. Defaults to
+	// 24 hours.
+	LeafLifetime time.Duration
+
+	// RenewBefore is how long before expiry a cached certificate returned by
+	// GetCertificate is replaced. Defaults to a third of LeafLifetime.
+	RenewBefore time.Duration
+
+	// KeyType selects the algorithm of the keys generated for issued
+	// certificates. Defaults to ECDSAP256.
+	KeyType KeyType
+
+	// HostPolicy, if not nil, is called by GetCertificate before issuing a
+	// certificate for a server name requested by a client, which is refused
+	// when it returns an error. Without it any name is issued, so that every
+	// client can make the server generate keys. See HostAllowList.
+	HostPolicy func(ctx context.Context, host string) error
+
+	// MaxCached bounds the number of certificates GetCertificate keeps,
+	// dropping expired certificates first, then the ones expiring first.
+	// Defaults to 1000.
+	MaxCached int
+
+	mu    sync.Mutex
+	cert  *x509.Certificate
+	key   crypto.Signer
+	cache map[string]*tls.Certificate
+}
+
+// NewCA combines a CA certificate and its private key, as parsed by
+// Certificate and RSAPrivateKey or ECDSAPrivateKey, into a CA.
+func NewCA(cert *x509.Certificate, key crypto.PrivateKey) (*CA, error) {
+	ca := &CA{}
+	if err := ca.reset(cert, key); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+// reset checks that key matches cert and that cert is allowed to sign other
+// certificates, then replaces the CA material and drops issued certificates.
+func (ca *CA) reset(cert *x509.Certificate, key crypto.PrivateKey) error {
+	if !cert.BasicConstraintsValid || !cert.IsCA {
+		return errors.New("certificate is not a certificate authority")
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return errors.New("unknown type of private key")
+	}
+	if !publicKeysEqual(cert.PublicKey, signer.Public()) {
+		return errors.New("private key does not match certificate")
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.cert = cert
+	ca.key = signer
+	ca.cache = nil
+	return nil
+}
+
+// Certificate returns the CA certificate.
+func (ca *CA) Certificate() *x509.Certificate {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	return ca.cert
+}
+
+// leafLifetime returns the configured lifetime of issued certificates.
+func (ca *CA) leafLifetime() time.Duration {
+	if ca.LeafLifetime <= 0 {
+		return 24 * time.Hour
+	}
+	return ca.LeafLifetime
+}
+
+// renewBefore returns how long before expiry cached certificates are
+// replaced.
+func (ca *CA) renewBefore() time.Duration {
+	if ca.RenewBefore <= 0 {
+		return ca.leafLifetime() / 3
+	}
+	return ca.RenewBefore
+}
+
+// Issue generates a fresh key and returns a certificate for it signed by the
+// CA. Template fields left empty are filled with a random serial number and
+// the configured lifetime, which never exceeds the one of the CA.
+func (ca *CA) Issue(template *x509.Certificate) (*tls.Certificate, error) {
+	ca.mu.Lock()
+	caCert, caKey := ca.cert, ca.key
+	ca.mu.Unlock()
+	if caCert == nil {
+		return nil, errors.New("certificate authority is not initialized")
+	}
+
+	tmpl := *template
+	if tmpl.SerialNumber == nil {
+		serial, err := randomSerialNumber()
+		if err != nil {
+			return nil, err
+		}
+		tmpl.SerialNumber = serial
+	}
+	if tmpl.NotBefore.IsZero() {
+		tmpl.NotBefore = time.Now().Add(-time.Minute)
+	}
+	if tmpl.NotAfter.IsZero() {
+		tmpl.NotAfter = tmpl.NotBefore.Add(ca.leafLifetime())
+	}
+	if tmpl.NotAfter.After(caCert.NotAfter) {
+		tmpl.NotAfter = caCert.NotAfter
+	}
+
+	key, err := generateKey(ca.KeyType)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.KeyUsage == 0 {
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+		if _, ok := key.(*rsa.PrivateKey); ok {
+			tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+		}
+	}
+	if len(tmpl.ExtKeyUsage) == 0 {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, caCert, key.Public(), caKey)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der, caCert.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// HostAllowList returns a policy for CA.HostPolicy which only allows the
+// given host names, compared case insensitively.
+func HostAllowList(hosts ...string) func(context.Context, string) error {
+	allowed := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		allowed[strings.ToLower(h)] = true
+	}
+	return func(_ context.Context, host string) error {
+		if !allowed[strings.ToLower(host)] {
+			return fmt.Errorf("host %q not allowed", host)
+		}
+		return nil
+	}
+}
+
+// maxCached returns the configured cache size or its default.
+func (ca *CA) maxCached() int {
+	if ca.MaxCached <= 0 {
+		return 1000
+	}
+	return ca.MaxCached
+}
+
+// GetCertificate can be used as tls.Config.GetCertificate. It returns a
+// certificate for the requested server name, issuing a new one when none is
+// cached or when the cached one is about to expire. Names are checked
+// against HostPolicy before issuing.
+func (ca *CA) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.ToLower(hello.ServerName)
+	if name == "" {
+		return nil, errors.New("missing server name")
+	}
+
+	ca.mu.Lock()
+	cert, ok := ca.cache[name]
+	ca.mu.Unlock()
+	if ok && time.Now().Before(cert.Leaf.NotAfter.Add(-ca.renewBefore())) {
+		return cert, nil
+	}
+
+	if ca.HostPolicy != nil {
+		ctx := hello.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if err := ca.HostPolicy(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+
+	template := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+	if ip := net.ParseIP(name); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{name}
+	}
+	cert, err := ca.Issue(template)
+	if err != nil {
+		return nil, err
+	}
+
+	ca.mu.Lock()
+	if ca.cache == nil {
+		ca.cache = make(map[string]*tls.Certificate)
+	}
+	if _, ok := ca.cache[name]; !ok {
+		ca.evict(ca.maxCached() - 1)
+	}
+	ca.cache[name] = cert
+	ca.mu.Unlock()
+
+	return cert, nil
+}
+
+// evict drops cached certificates until at most max are left: expired ones
+// first, then the ones expiring first. ca.mu must be held.
+func (ca *CA) evict(max int) {
+	now := time.Now()
+	for name, cert := range ca.cache {
+		if len(ca.cache) <= max {
+			return
+		}
+		if now.After(cert.Leaf.NotAfter) {
+			delete(ca.cache, name)
+		}
+	}
+	for len(ca.cache) > max {
+		var oldest string
+		for name, cert := range ca.cache {
+			if oldest == "" || cert.Leaf.NotAfter.Before(ca.cache[oldest].Leaf.NotAfter) {
+				oldest = name
+			}
+		}
+		delete(ca.cache, oldest)
+	}
+}
+
+// caValue adapts CA for use as a flag. Value of flag is PEM encoded and holds
+// both the private key and the CA certificate.
+type caValue struct {
+	dst    *CA
+	source string
+}
+
+// String implements flag.Value.String.
+func (v caValue) String() string {
+	if v.source != "" {
+		return v.source
+	}
+	if v.dst == nil {
+		return ""
+	}
+	cert := v.dst.Certificate()
+	if cert == nil {
+		return ""
+	}
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: cert.Raw,
+	}))
+}
+
+// Set implements flag.Value.Set.
+func (v *caValue) Set(value string) error {
+	data, source, err := resolvePrivate(value, 0)
+	if err != nil {
+		return err
+	}
+	var (
+		cert *x509.Certificate
+		key  crypto.Signer
+	)
+	for len(data) > 0 {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE" && cert == nil:
+			if cert, err = x509.ParseCertificate(block.Bytes); err != nil {
+				return err
+			}
+		case strings.HasSuffix(block.Type, "PRIVATE KEY") && key == nil:
+			if key, err = parsePrivateKey(block); err != nil {
+				return err
+			}
+		}
+	}
+	if cert == nil || key == nil {
+		return errors.New("failed to find a suitable pem block type")
+	}
+
+	if err := v.dst.reset(cert, key); err != nil {
+		return err
+	}
+	v.source = source
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (*caValue) Type() string {
+	return TypeCertificateAuthority
+}
+
+// Get implements flag.Getter. It returns a *CA.
+func (v caValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *caValue) sourceRef() string {
+	return v.source
+}
+
+// CertificateAuthority creates and returns a new flag.Value compliant CA
+// parser. The value must contain a PEM encoded private key and the
+// certificate of the authority it belongs to.
+func CertificateAuthority(ca *CA) flag.Value {
+	return &caValue{dst: ca}
+}
@@ -0,0 +1,152 @@
+package flagvars
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestCA(t *testing.T) *tls.Certificate {
+	cert, err := generateSelfSigned([]string{"Test CA"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	return cert
+}
+
+func TestCAIssue(t *testing.T) {
+	caCert := newTestCA(t)
+	ca, err := NewCA(caCert.Leaf, caCert.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	cert, err := ca.Issue(&x509.Certificate{
+		Subject:  pkix.Name{CommonName: "service"},
+		DNSNames: []string{"service.local"},
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert.Leaf)
+	if _, err := cert.Leaf.Verify(x509.VerifyOptions{DNSName: "service.local", Roots: roots}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if cert.Leaf.NotAfter.After(caCert.Leaf.NotAfter) {
+		t.Fatalf("got: %v, expected at most %v", cert.Leaf.NotAfter, caCert.Leaf.NotAfter)
+	}
+}
+
+func TestCAGetCertificate(t *testing.T) {
+	caCert := newTestCA(t)
+	ca, err := NewCA(caCert.Leaf, caCert.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	ca.LeafLifetime = time.Hour
+
+	hello := &tls.ClientHelloInfo{ServerName: "example.local"}
+	first, err := ca.GetCertificate(hello)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	second, err := ca.GetCertificate(hello)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if first != second {
+		t.Fatalf("expected cached certificate to be reused")
+	}
+
+	ca.RenewBefore = 2 * time.Hour
+	third, err := ca.GetCertificate(hello)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if third == second {
+		t.Fatalf("expected certificate close to expiry to be renewed")
+	}
+
+	if _, err := ca.GetCertificate(&tls.ClientHelloInfo{}); err == nil {
+		t.Fatalf("expected failure without server name")
+	}
+}
+
+func TestCAHostPolicy(t *testing.T) {
+	caCert := newTestCA(t)
+	ca, err := NewCA(caCert.Leaf, caCert.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	ca.HostPolicy = HostAllowList("a.local", "B.local", "c.local")
+	ca.MaxCached = 2
+
+	for _, name := range []string{"a.local", "b.local", "C.LOCAL"} {
+		if _, err := ca.GetCertificate(&tls.ClientHelloInfo{ServerName: name}); err != nil {
+			t.Fatalf("expected success, got %q", err)
+		}
+	}
+	if _, err := ca.GetCertificate(&tls.ClientHelloInfo{ServerName: "evil.local"}); err == nil {
+		t.Fatalf("expected failure with host not allowed")
+	}
+	if len(ca.cache) != 2 {
+		t.Fatalf("got: %d cached certificates, expected 2", len(ca.cache))
+	}
+	if _, ok := ca.cache["evil.local"]; ok {
+		t.Fatalf("expected refused host not to be cached")
+	}
+}
+
+func TestNewCAValidation(t *testing.T) {
+	caCert := newTestCA(t)
+	otherCert := newTestCA(t)
+
+	if _, err := NewCA(caCert.Leaf, otherCert.PrivateKey); err == nil {
+		t.Fatalf("expected failure with mismatching key")
+	}
+
+	ca, _ := NewCA(caCert.Leaf, caCert.PrivateKey)
+	leaf, _ := ca.Issue(&x509.Certificate{DNSNames: []string{"leaf"}})
+	if _, err := NewCA(leaf.Leaf, leaf.PrivateKey); err == nil {
+		t.Fatalf("expected failure with non CA certificate")
+	}
+}
+
+func TestCertificateAuthority(t *testing.T) {
+	caCert := newTestCA(t)
+	keyDer, err := x509.MarshalPKCS8PrivateKey(caCert.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Leaf.Raw})
+
+	var ca CA
+	caValue := CertificateAuthority(&ca)
+	if err := caValue.Set(fmt.Sprintf("%s%s", keyPEM, certPEM)); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !ca.Certificate().Equal(caCert.Leaf) {
+		t.Fatalf("got: %v, expected %v", ca.Certificate(), caCert.Leaf)
+	}
+	if caValue.String() != string(certPEM) {
+		t.Fatalf("got: %q, expected %q", caValue.String(), certPEM)
+	}
+
+	if err := caValue.Set(string(certPEM)); err == nil {
+		t.Fatalf("expected failure without private key")
+	}
+}
+
+func TestCertificateAuthorityVar(t *testing.T) {
+	var ca CA
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Var(CertificateAuthority(&ca), "ca", "certificate authority")
+}
@@ -2,19 +2,20 @@ package flagvars
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"flag"
 	"fmt"
-	"strings"
 )
 
 // certificateValue adapts x509.Certificate for use as a flag. Value of flag
 // is PEM encoded.
 type certificateValue struct {
-	dst *x509.Certificate
+	dst    *x509.Certificate
+	source string
 }
 
 // String implements flag.Value.String.
@@ -22,6 +23,15 @@ func (v certificateValue) String() string {
 	if v.dst == nil {
 		return ""
 	}
+	if StringFormat == FormatSummary {
+		if v.dst.Raw == nil {
+			return ""
+		}
+		return summarizeCertificate(v.dst)
+	}
+	if v.source != "" {
+		return v.source
+	}
 	return string(pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
 		Bytes: v.dst.Raw,
@@ -30,8 +40,11 @@ func (v certificateValue) String() string {
 
 // Set implements flag.Value.Set.
 func (v *certificateValue) Set(value string) error {
-	value = strings.ReplaceAll(value, `\n`, "\n")
-	block, _ := pem.Decode([]byte(value))
+	data, source, err := resolve(value)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
 	if block == nil || block.Type != "CERTIFICATE" {
 		return errors.New("failed to find a suitable pem block type")
 	}
@@ -41,12 +54,28 @@ func (v *certificateValue) Set(value string) error {
 		return err
 	}
 	*v.dst = *cert
+	v.source = source
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*certificateValue) Type() string {
-	return "certificate"
+	return TypeCertificate
+}
+
+// Get implements flag.Getter. It returns a *x509.Certificate.
+func (v certificateValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *certificateValue) sourceRef() string {
+	return v.source
+}
+
+// publicKey implements publicKeyHolder.
+func (v *certificateValue) publicKey() crypto.PublicKey {
+	return v.dst.PublicKey
 }
 
 // Certificate creates and returns a new flag.Value compliant Certificates
@@ -58,13 +87,29 @@ func Certificate(c *x509.Certificate) flag.Value {
 // certificatesValue adapts arrays of x509.Certificate for use as a flag.
 // Value of flag is PEM encoded.
 type certificatesValue struct {
-	dst *[]*x509.Certificate
+	dst    *[]*x509.Certificate
+	source string
 }
 
 // String implements flag.Value.String.
 func (v certificatesValue) String() string {
+	if v.dst == nil {
+		return ""
+	}
+	if v.source != "" && StringFormat != FormatSummary {
+		return v.source
+	}
+	return formatCertificates(*v.dst)
+}
+
+// formatCertificates renders certs according to StringFormat.
+func formatCertificates(certs []*x509.Certificate) string {
 	var buf bytes.Buffer
-	for _, c := range *v.dst {
+	for _, c := range certs {
+		if StringFormat == FormatSummary {
+			fmt.Fprintln(&buf, summarizeCertificate(c))
+			continue
+		}
 		fmt.Fprintln(&buf, string(pem.EncodeToMemory(&pem.Block{
 			Type:  "CERTIFICATE",
 			Bytes: c.Raw,
@@ -75,8 +120,10 @@ func (v certificatesValue) String() string {
 
 // Set implements flag.Value.Set.
 func (v *certificatesValue) Set(value string) error {
-	value = strings.ReplaceAll(value, `\n`, "\n")
-	data := []byte(value)
+	data, source, err := resolve(value)
+	if err != nil {
+		return err
+	}
 	var blocks []byte
 	for len(data) > 0 {
 		var block *pem.Block
@@ -92,12 +139,32 @@ func (v *certificatesValue) Set(value string) error {
 		return err
 	}
 	*v.dst = certs
+	v.source = source
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*certificatesValue) Type() string {
-	return "certificates"
+	return TypeCertificates
+}
+
+// Get implements flag.Getter. It returns a []*x509.Certificate.
+func (v certificatesValue) Get() interface{} {
+	return *v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *certificatesValue) sourceRef() string {
+	return v.source
+}
+
+// publicKey implements publicKeyHolder. The first certificate is expected
+// to be the leaf.
+func (v *certificatesValue) publicKey() crypto.PublicKey {
+	if len(*v.dst) == 0 {
+		return nil
+	}
+	return (*v.dst)[0].PublicKey
 }
 
 // Certificates creates and returns a new flag.Value compliant Certificates
@@ -109,7 +176,9 @@ func Certificates(c *[]*x509.Certificate) flag.Value {
 // certPoolValue adapts x509.CertPool for use as a flag. Value of flag
 // is PEM encoded.
 type certPoolValue struct {
-	dst *x509.CertPool
+	dst    *x509.CertPool
+	certs  []*x509.Certificate
+	source string
 }
 
 // String implements flag.Value.String.
@@ -117,27 +186,65 @@ func (v certPoolValue) String() string {
 	if v.dst == nil {
 		return ""
 	}
+	if v.source != "" && StringFormat != FormatSummary {
+		return v.source
+	}
+	if len(v.certs) > 0 {
+		return formatCertificates(v.certs)
+	}
 	var buf bytes.Buffer
 	for _, s := range (*v.dst).Subjects() {
-		fmt.Fprintln(&buf, string(s))
+		fmt.Fprintln(&buf, describeSubject(s))
 	}
 	return buf.String()
 }
 
 // Set implements flag.Value.Set.
 func (v *certPoolValue) Set(value string) error {
-	value = strings.ReplaceAll(value, `\n`, "\n")
+	data, source, err := resolve(value)
+	if err != nil {
+		return err
+	}
 	pool := x509.NewCertPool()
-	if ok := pool.AppendCertsFromPEM([]byte(value)); !ok {
+	var certs []*x509.Certificate
+	for len(data) > 0 {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" || len(block.Headers) != 0 {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		pool.AddCert(cert)
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
 		return fmt.Errorf("failed to append certs from pem")
 	}
 	*v.dst = *pool
+	v.certs = certs
+	v.source = source
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*certPoolValue) Type() string {
-	return "certpool"
+	return TypeCertPool
+}
+
+// Get implements flag.Getter. It returns a *x509.CertPool.
+func (v certPoolValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *certPoolValue) sourceRef() string {
+	return v.source
 }
 
 // CertPool creates and returns a new flag.Value compliant AppendCertsFromPEM
@@ -149,19 +256,37 @@ func CertPool(c *x509.CertPool) flag.Value {
 // tlsCertificateValue adapts tls.Certificate for use as a flag. Value of flag
 // is PEM encoded.
 type tlsCertificateValue struct {
-	dst *tls.Certificate
+	dst    *tls.Certificate
+	source string
 }
 
 // String implements flag.Value.String.
 func (v tlsCertificateValue) String() string {
+	if StringFormat == FormatSummary {
+		if v.dst == nil || len(v.dst.Certificate) == 0 {
+			return ""
+		}
+		leaf := v.dst.Leaf
+		if leaf == nil {
+			var err error
+			if leaf, err = x509.ParseCertificate(v.dst.Certificate[0]); err != nil {
+				return ""
+			}
+		}
+		return fmt.Sprintf("subject=%q sha256=%s", leaf.Subject.String(), fingerprint(leaf.Raw))
+	}
+	if v.source != "" {
+		return v.source
+	}
 	return "<redacted>"
 }
 
 // Set implements flag.Value.Set.
 func (v *tlsCertificateValue) Set(value string) error {
-	var block *pem.Block
-	value = strings.ReplaceAll(value, `\n`, "\n")
-	data := []byte(value)
+	data, source, err := resolvePrivate(value, 0)
+	if err != nil {
+		return err
+	}
 	block, certPEM := pem.Decode(data)
 
 	// First block must be a private key
@@ -175,12 +300,32 @@ func (v *tlsCertificateValue) Set(value string) error {
 		return err
 	}
 	*v.dst = cert
+	v.source = source
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*tlsCertificateValue) Type() string {
-	return "tls certificate"
+	return TypeTLSCertificate
+}
+
+// Get implements flag.Getter. It returns a *tls.Certificate.
+func (v tlsCertificateValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *tlsCertificateValue) sourceRef() string {
+	return v.source
+}
+
+// publicKey implements publicKeyHolder.
+func (v *tlsCertificateValue) publicKey() crypto.PublicKey {
+	signer, ok := v.dst.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil
+	}
+	return signer.Public()
 }
 
 // TLSCertificate creates and returns a new flag.Value compliant X509KeyPair
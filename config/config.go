@@ -0,0 +1,307 @@
+// Package config sets flags, flagvars values included, from YAML, JSON and
+// TOML configuration files.
+//
+// Keys map to flag names, nested sections being joined by a dash or a dot,
+// so that both of the following set the -tls-cert flag:
+//
+//	tls-cert: /etc/app/tls.crt
+//
+//	tls:
+//	  cert: |
+//	    -----BEGIN CERTIFICATE-----
+//	    ...
+//	    -----END CERTIFICATE-----
+//
+// Values are passed to the Set method of each flag.Value, as fs.Parse does,
+// so every source understood by flagvars is available in files as well.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies the syntax of a configuration file.
+type Format int
+
+const (
+	// YAML files are parsed as YAML 1.2.
+	YAML Format = iota
+	// JSON files are parsed as JSON, which is a subset of YAML 1.2.
+	JSON
+	// TOML files are parsed as TOML 1.0.
+	TOML
+)
+
+// String implements fmt.Stringer.
+func (f Format) String() string {
+	switch f {
+	case YAML:
+		return "yaml"
+	case JSON:
+		return "json"
+	case TOML:
+		return "toml"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// FormatOf returns the format of the file at path based on its extension.
+func FormatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".json":
+		return JSON, nil
+	case ".toml":
+		return TOML, nil
+	}
+	return 0, fmt.Errorf("%s: unknown configuration file format", path)
+}
+
+// Load reads the configuration file at path, whose format is picked from its
+// extension, and sets the flags of fs from it.
+func Load(fs *flag.FlagSet, path string) error {
+	format, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return Parse(fs, path, data, format)
+}
+
+// Parse sets the flags of fs from data, a configuration file in the given
+// format. name is used to report errors, along with the line they refer to.
+//
+// Flags already set, on the command line for example, are left untouched.
+// When Parse is called before fs.Parse, values from the file become the
+// defaults the command line may override.
+func Parse(fs *flag.FlagSet, name string, data []byte, format Format) error {
+	var (
+		entries []entry
+		err     error
+	)
+	switch format {
+	case YAML, JSON:
+		entries, err = parseYAML(data)
+	case TOML:
+		entries, err = parseTOML(data)
+	default:
+		return fmt.Errorf("%s: unknown configuration file format", name)
+	}
+	if err != nil {
+		return fmt.Errorf("%s:%v", name, err)
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var msgs []string
+	for _, e := range entries {
+		if e.err != nil {
+			msgs = append(msgs, fmt.Sprintf("%s:%d: %s: %v", name, e.line, e.key(), e.err))
+			continue
+		}
+		f := lookup(fs, e.path)
+		if f == nil {
+			msgs = append(msgs, fmt.Sprintf("%s:%d: unknown key %s", name, e.line, e.key()))
+			continue
+		}
+		if set[f.Name] {
+			continue
+		}
+		if err := setFlag(fs, f, e.values); err != nil {
+			msgs = append(msgs, fmt.Sprintf("%s:%d: invalid value for key %s (flag -%s): %v", name, e.line, e.key(), f.Name, err))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
+
+// lookup returns the flag of fs named after path, its elements joined by a
+// dash or a dot.
+func lookup(fs *flag.FlagSet, path []string) *flag.Flag {
+	for _, sep := range []string{"-", "."} {
+		if f := fs.Lookup(strings.Join(path, sep)); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// setFlag calls the Set method of f once per value. As with BindEnv, values
+// set before fs.Parse become the defaults of the flags, though not the ones
+// printed by flag.PrintDefaults, which would show keys from the file.
+func setFlag(fs *flag.FlagSet, f *flag.Flag, values []string) error {
+	for _, v := range values {
+		if fs.Parsed() {
+			if err := fs.Set(f.Name, v); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := f.Value.Set(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entry is a leaf of a configuration file.
+type entry struct {
+	path   []string
+	values []string
+	line   int
+	err    error
+}
+
+// key returns the dotted key of e as written in the file.
+func (e entry) key() string {
+	return strings.Join(e.path, ".")
+}
+
+// parseYAML flattens a YAML or JSON document into its leaves.
+func parseYAML(data []byte) ([]entry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// Syntax errors read "yaml: line N: ...".
+		if msg := err.Error(); strings.HasPrefix(msg, "yaml: line ") {
+			return nil, errors.New(strings.TrimPrefix(msg, "yaml: line "))
+		}
+		return nil, fmt.Errorf(" %v", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%d: expected a mapping at the top level", root.Line)
+	}
+	var entries []entry
+	walkYAML(root, nil, &entries)
+	return entries, nil
+}
+
+// walkYAML appends the leaves of the mapping node to entries.
+func walkYAML(node *yaml.Node, path []string, entries *[]entry) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		p := append(append([]string(nil), path...), key.Value)
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+
+		switch value.Kind {
+		case yaml.MappingNode:
+			walkYAML(value, p, entries)
+		case yaml.SequenceNode:
+			e := entry{path: p, line: key.Line}
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					e.err = fmt.Errorf("expected a list of scalars")
+					break
+				}
+				e.values = append(e.values, item.Value)
+			}
+			*entries = append(*entries, e)
+		case yaml.ScalarNode:
+			if value.Tag == "!!null" {
+				continue
+			}
+			*entries = append(*entries, entry{path: p, values: []string{value.Value}, line: key.Line})
+		}
+	}
+}
+
+// parseTOML flattens a TOML document into its leaves.
+func parseTOML(data []byte) ([]entry, error) {
+	var (
+		p       unstable.Parser
+		entries []entry
+		table   []string
+	)
+	p.Reset(data)
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.Table:
+			table, _ = tomlKey(&p, expr.Key())
+		case unstable.ArrayTable:
+			path, line := tomlKey(&p, expr.Key())
+			entries = append(entries, entry{path: path, line: line, err: errors.New("arrays of tables are not supported")})
+			table = path
+		case unstable.KeyValue:
+			key, line := tomlKey(&p, expr.Key())
+			path := append(append([]string(nil), table...), key...)
+			walkTOML(&p, expr.Value(), path, line, &entries)
+		}
+	}
+	if err := p.Error(); err != nil {
+		// Decoding again provides the position of the error.
+		var derr *toml.DecodeError
+		if errors.As(toml.Unmarshal(data, new(map[string]interface{})), &derr) {
+			line, _ := derr.Position()
+			return nil, fmt.Errorf("%d: %v", line, derr)
+		}
+		return nil, fmt.Errorf(" %v", err)
+	}
+	return entries, nil
+}
+
+// tomlKey returns the parts of a possibly dotted key along with its line.
+func tomlKey(p *unstable.Parser, it unstable.Iterator) ([]string, int) {
+	var (
+		path []string
+		line int
+	)
+	for it.Next() {
+		n := it.Node()
+		if line == 0 {
+			line = p.Shape(n.Raw).Start.Line
+		}
+		path = append(path, string(n.Data))
+	}
+	return path, line
+}
+
+// walkTOML appends the leaves of the value node to entries.
+func walkTOML(p *unstable.Parser, value *unstable.Node, path []string, line int, entries *[]entry) {
+	switch value.Kind {
+	case unstable.InlineTable:
+		it := value.Children()
+		for it.Next() {
+			kv := it.Node()
+			key, l := tomlKey(p, kv.Key())
+			walkTOML(p, kv.Value(), append(append([]string(nil), path...), key...), l, entries)
+		}
+	case unstable.Array:
+		e := entry{path: path, line: line}
+		it := value.Children()
+		for it.Next() {
+			item := it.Node()
+			if item.Kind == unstable.Array || item.Kind == unstable.InlineTable {
+				e.err = fmt.Errorf("expected a list of scalars")
+				break
+			}
+			e.values = append(e.values, string(item.Data))
+		}
+		*entries = append(*entries, e)
+	default:
+		*entries = append(*entries, entry{path: path, values: []string{string(value.Data)}, line: line})
+	}
+}
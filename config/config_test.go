@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glerchundi/flagvars"
+)
+
+func testCertificatePEM(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+type testFlags struct {
+	fs   *flag.FlagSet
+	cert x509.Certificate
+	key  []byte
+	name *string
+}
+
+func newTestFlags() *testFlags {
+	f := &testFlags{fs: flag.NewFlagSet("test", flag.ContinueOnError)}
+	f.fs.Var(flagvars.Certificate(&f.cert), "tls-cert", "certificate")
+	f.fs.Var(flagvars.BytesHex(&f.key, nil), "hmac.key", "HMAC key")
+	f.name = f.fs.String("name", "", "name")
+	return f
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n"+prefix)
+}
+
+func TestParse(t *testing.T) {
+	certPEM := testCertificatePEM(t)
+
+	testCases := []struct {
+		format Format
+		data   string
+	}{
+		{YAML, "tls:\n  cert: |\n" + indent(certPEM, "    ") + "\nhmac:\n  key: \"0102\"\nname: service\n"},
+		{JSON, `{"tls": {"cert": "` + strings.ReplaceAll(certPEM, "\n", `\n`) + `"}, "hmac.key": "0102", "name": "service"}`},
+		{TOML, "name = \"service\"\n\n[tls]\ncert = \"\"\"\n" + certPEM + "\"\"\"\n\n[hmac]\nkey = \"0102\"\n"},
+	}
+
+	for _, tc := range testCases {
+		f := newTestFlags()
+		if err := Parse(f.fs, "config", []byte(tc.data), tc.format); err != nil {
+			t.Errorf("expected success with %v, got %q", tc.format, err)
+			continue
+		}
+		block, _ := pem.Decode([]byte(certPEM))
+		if !bytes.Equal(f.cert.Raw, block.Bytes) {
+			t.Errorf("expected certificate to be set with %v", tc.format)
+		}
+		if !bytes.Equal(f.key, []byte{1, 2}) || *f.name != "service" {
+			t.Errorf("got: %X, %q, expected 0102, %q with %v", f.key, *f.name, "service", tc.format)
+		}
+	}
+}
+
+// stringsValue collects every value it is set with.
+type stringsValue []string
+
+func (s *stringsValue) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsValue) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func TestParseTOMLStrings(t *testing.T) {
+	var list stringsValue
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "name")
+	fs.Var(&list, "list", "list")
+	if err := Parse(fs, "config", []byte("name = \"  sp \"\nlist = [\"  sp \", \"x\"]\n"), TOML); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if *name != "  sp " || len(list) != 2 || list[0] != "  sp " || list[1] != "x" {
+		t.Fatalf("got: %q, %q, expected strings to be kept verbatim", *name, list)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		format   Format
+		data     string
+		expected []string
+	}{
+		{YAML, "name: service\ntls:\n  crt: x\n", []string{"config:3: unknown key tls.crt"}},
+		{YAML, "name: service\n\nhmac:\n  key: zz\n", []string{"config:4: invalid value for key hmac.key (flag -hmac.key)"}},
+		{YAML, "name: [a, [b]]\n", []string{"config:1: name: expected a list of scalars"}},
+		{YAML, "name: service\n  bad: indent\n", []string{"config:2:"}},
+		{TOML, "name = \"service\"\n[tls]\ncrt = \"x\"\n", []string{"config:3: unknown key tls.crt"}},
+		{TOML, "[hmac]\n\nkey = \"zz\"\n", []string{"config:3: invalid value for key hmac.key"}},
+		{TOML, "name = \n", []string{"config:1:"}},
+		{JSON, "{\"name\": \"a\",\n \"other\": 1}", []string{"config:2: unknown key other"}},
+	}
+
+	for _, tc := range testCases {
+		err := Parse(newTestFlags().fs, "config", []byte(tc.data), tc.format)
+		if err == nil {
+			t.Errorf("expected failure while processing %q", tc.data)
+			continue
+		}
+		for _, expected := range tc.expected {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("got: %q, expected to contain %q", err, expected)
+			}
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("name: from-file\nhmac.key: \"0102\"\n"), 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	f := newTestFlags()
+	if err := Load(f.fs, path); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := f.fs.Parse([]string{"-name", "from-cli"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if *f.name != "from-cli" || !bytes.Equal(f.key, []byte{1, 2}) {
+		t.Fatalf("got: %q, %X, expected %q, 0102", *f.name, f.key, "from-cli")
+	}
+	var usage bytes.Buffer
+	f.fs.SetOutput(&usage)
+	f.fs.PrintDefaults()
+	if strings.Contains(usage.String(), "0102") {
+		t.Fatalf("got: %q, expected values from the file not to be printed", usage.String())
+	}
+
+	f = newTestFlags()
+	if err := f.fs.Parse([]string{"-name", "from-cli"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := Load(f.fs, path); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if *f.name != "from-cli" || !bytes.Equal(f.key, []byte{1, 2}) {
+		t.Fatalf("got: %q, %X, expected %q, 0102", *f.name, f.key, "from-cli")
+	}
+
+	if err := Load(f.fs, filepath.Join(t.TempDir(), "config.ini")); err == nil {
+		t.Fatalf("expected failure with unknown format")
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/glerchundi/flagvars/config
+
+go 1.18
+
+require (
+	github.com/glerchundi/flagvars v0.0.0-00010101000000-000000000000
+	github.com/pelletier/go-toml/v2 v2.2.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/glerchundi/flagvars => ../
@@ -0,0 +1,72 @@
+package flagvars
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDockerSecretsDir is where Docker and Docker Swarm mount secrets.
+const DefaultDockerSecretsDir = "/run/secrets"
+
+// CredentialResolver resolves references as the names of systemd
+// credentials, loaded through LoadCredential= or SetCredential= into the
+// directory named by $CREDENTIALS_DIRECTORY. It handles the credential
+// scheme by default.
+type CredentialResolver struct{}
+
+// Resolve implements Resolver.Resolve.
+func (CredentialResolver) Resolve(_ context.Context, name string) ([]byte, error) {
+	dir, ok := os.LookupEnv("CREDENTIALS_DIRECTORY")
+	if !ok || dir == "" {
+		return nil, fmt.Errorf("credential %s: CREDENTIALS_DIRECTORY is not set, is LoadCredential= configured for the service?", name)
+	}
+	// systemd makes credentials readable by the service user only.
+	return readSecretFile(dir, name, 0077)
+}
+
+// DockerSecretResolver resolves references as the names of Docker secrets.
+// It handles the secret scheme by default.
+type DockerSecretResolver struct {
+	// Dir is the directory secrets are mounted at. Defaults to
+	// DefaultDockerSecretsDir.
+	Dir string
+}
+
+// Resolve implements Resolver.Resolve.
+func (r DockerSecretResolver) Resolve(_ context.Context, name string) ([]byte, error) {
+	dir := r.Dir
+	if dir == "" {
+		dir = DefaultDockerSecretsDir
+	}
+	// Docker mounts secrets world-readable by default, so only group- or
+	// world-writable files are refused. Ownership is not checked.
+	return readSecretFile(dir, name, 0022)
+}
+
+// readSecretFile reads the regular file name from dir, refusing names which
+// escape dir and files whose mode has any of the forbidden permission bits.
+func readSecretFile(dir, name string, forbidden os.FileMode) ([]byte, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid secret name %q", name)
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return nil, fmt.Errorf("secret %s: directory %s does not exist", name, dir)
+	}
+
+	path := filepath.Join(dir, name)
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %v", name, err)
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, fmt.Errorf("secret %s: %s is not a regular file", name, path)
+	}
+	if perm := fi.Mode().Perm(); perm&forbidden != 0 {
+		return nil, fmt.Errorf("secret %s: %s has mode %04o, expected at most %04o", name, path, perm, os.ModePerm&^forbidden)
+	}
+	return ioutil.ReadFile(path)
+}
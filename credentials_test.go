@@ -0,0 +1,84 @@
+package flagvars
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCredentialResolver(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "hmac"), []byte("0102"), 0400); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "shared"), []byte("0304"), 0440); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	var b []byte
+	hexValue := BytesHex(&b, nil)
+
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+	if err := hexValue.Set("credential:hmac"); err == nil || !strings.Contains(err.Error(), "CREDENTIALS_DIRECTORY is not set") {
+		t.Fatalf("got: %v, expected missing directory failure", err)
+	}
+
+	t.Setenv("CREDENTIALS_DIRECTORY", dir)
+	if err := hexValue.Set("credential:hmac"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(b, []byte{1, 2}) || hexValue.String() != "credential:hmac" {
+		t.Fatalf("got: %X from %q, expected 0102", b, hexValue.String())
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"credential:shared", "has mode 0440, expected at most 0700"},
+		{"credential:missing", "no such file"},
+		{"credential:../hmac", "invalid secret name"},
+	}
+	for _, tc := range testCases {
+		if err := hexValue.Set(tc.input); err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("got: %v, expected error containing %q while processing %q", err, tc.expected, tc.input)
+		}
+	}
+}
+
+func TestDockerSecretResolver(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "hmac"), []byte("0102"), 0444); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "writable"), []byte("0304"), 0666); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	if err := os.Chmod(filepath.Join(dir, "writable"), 0666); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	var b []byte
+	hexValue := BytesHex(&b, nil)
+
+	RegisterResolver("secret", DockerSecretResolver{Dir: filepath.Join(dir, "missing")})
+	defer RegisterResolver("secret", DockerSecretResolver{})
+	if err := hexValue.Set("secret:hmac"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("got: %v, expected missing directory failure", err)
+	}
+
+	RegisterResolver("secret", DockerSecretResolver{Dir: dir})
+	if err := hexValue.Set("secret:hmac"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(b, []byte{1, 2}) {
+		t.Fatalf("got: %X, expected 0102", b)
+	}
+	if err := hexValue.Set("secret:writable"); err == nil {
+		t.Fatalf("expected failure with writable secret")
+	}
+}
@@ -1,29 +1,42 @@
 package flagvars
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"flag"
-	"strings"
 )
 
 // ecdsaPrivateKeyValue adapts ecdsa.PrivateKey for use as a flag. Value of flag
 // is PEM encoded.
 type ecdsaPrivateKeyValue struct {
-	dst *ecdsa.PrivateKey
+	dst    *ecdsa.PrivateKey
+	source string
 }
 
 // String implements flag.Value.String.
 func (v ecdsaPrivateKeyValue) String() string {
+	if StringFormat == FormatSummary {
+		if v.dst == nil || v.dst.Curve == nil {
+			return ""
+		}
+		return summarizePublicKey(&v.dst.PublicKey)
+	}
+	if v.source != "" {
+		return v.source
+	}
 	return "<redacted>"
 }
 
 // Set implements flag.Value.Set.
 func (v *ecdsaPrivateKeyValue) Set(value string) error {
-	value = strings.ReplaceAll(value, `\n`, "\n")
-	block, _ := pem.Decode([]byte(value))
+	data, source, err := resolvePrivate(value, 0)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
 	if block == nil || (block.Type != "PRIVATE KEY" && block.Type != "EC PRIVATE KEY") {
 		return errors.New("failed to find a suitable pem block type")
 	}
@@ -33,13 +46,32 @@ func (v *ecdsaPrivateKeyValue) Set(value string) error {
 		return err
 	}
 	*v.dst = *priv
+	v.source = source
 
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*ecdsaPrivateKeyValue) Type() string {
-	return "ecdsaPrivateKey"
+	return TypeECDSAPrivateKey
+}
+
+// Get implements flag.Getter. It returns a *ecdsa.PrivateKey.
+func (v ecdsaPrivateKeyValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *ecdsaPrivateKeyValue) sourceRef() string {
+	return v.source
+}
+
+// publicKey implements publicKeyHolder.
+func (v *ecdsaPrivateKeyValue) publicKey() crypto.PublicKey {
+	if v.dst.Curve == nil {
+		return nil
+	}
+	return &v.dst.PublicKey
 }
 
 // ECDSAPrivateKey creates and returns a new flag.Value compliant ECDSA
@@ -51,11 +83,15 @@ func ECDSAPrivateKey(p *ecdsa.PrivateKey) flag.Value {
 // ecdsaPublicKeyValue adapts ecdsa.PublicKey for use as a flag. Value of flag
 // is PEM encoded.
 type ecdsaPublicKeyValue struct {
-	dst *ecdsa.PublicKey
+	dst    *ecdsa.PublicKey
+	source string
 }
 
 // String implements flag.Value.String.
 func (v ecdsaPublicKeyValue) String() string {
+	if v.source != "" {
+		return v.source
+	}
 	if v.dst == nil || (*v.dst == ecdsa.PublicKey{}) {
 		return ""
 	}
@@ -68,8 +104,11 @@ func (v ecdsaPublicKeyValue) String() string {
 
 // Set implements flag.Value.Set.
 func (v *ecdsaPublicKeyValue) Set(value string) error {
-	value = strings.ReplaceAll(value, `\n`, "\n")
-	block, _ := pem.Decode([]byte(value))
+	data, source, err := resolve(value)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
 	if block == nil || block.Type != "PUBLIC KEY" {
 		return errors.New("failed to find a suitable pem block type")
 	}
@@ -84,13 +123,32 @@ func (v *ecdsaPublicKeyValue) Set(value string) error {
 	default:
 		return errors.New("unknown type of public key")
 	}
+	v.source = source
 
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*ecdsaPublicKeyValue) Type() string {
-	return "ecdsaPublicKey"
+	return TypeECDSAPublicKey
+}
+
+// Get implements flag.Getter. It returns a *ecdsa.PublicKey.
+func (v ecdsaPublicKeyValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *ecdsaPublicKeyValue) sourceRef() string {
+	return v.source
+}
+
+// publicKey implements publicKeyHolder.
+func (v *ecdsaPublicKeyValue) publicKey() crypto.PublicKey {
+	if v.dst.Curve == nil {
+		return nil
+	}
+	return v.dst
 }
 
 // ECDSAPublicKey creates and returns a new flag.Value compliant ECDSA PublicKey
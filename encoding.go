@@ -0,0 +1,340 @@
+package flagvars
+
+import (
+	"encoding/ascii85"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Encoding converts bytes to and from text. *base64.Encoding and
+// *base32.Encoding implement it, among others.
+type Encoding interface {
+	EncodeToString(src []byte) string
+	DecodeString(s string) ([]byte, error)
+}
+
+// bytesEncodedValue adapts []byte for use as a flag. Value of flag is encoded
+// with enc.
+type bytesEncodedValue struct {
+	dst    *[]byte
+	enc    Encoding
+	typ    string
+	source string
+	// inline is true for encodings, such as Ascii85, whose alphabet holds
+	// the characters introducing sources, whose values are never resolved.
+	inline bool
+}
+
+// String implements flag.Value.String.
+func (v bytesEncodedValue) String() string {
+	if v.source != "" {
+		return v.source
+	}
+	if v.dst == nil || v.enc == nil {
+		return ""
+	}
+	return v.enc.EncodeToString(*v.dst)
+}
+
+// Set implements flag.Value.Set.
+func (v *bytesEncodedValue) Set(value string) error {
+	raw, source := []byte(value), ""
+	if !v.inline {
+		var err error
+		raw, source, err = resolvePrivate(value, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := v.enc.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return err
+	}
+
+	*v.dst = data
+	v.source = source
+
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (v *bytesEncodedValue) Type() string {
+	return v.typ
+}
+
+// Get implements flag.Getter. It returns a []byte.
+func (v bytesEncodedValue) Get() interface{} {
+	return *v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *bytesEncodedValue) sourceRef() string {
+	return v.source
+}
+
+// newBytesEncoded sets *p to value and returns a bytesEncodedValue of type
+// typ.
+func newBytesEncoded(p *[]byte, value []byte, enc Encoding, typ string) flag.Value {
+	*p = value
+	_, inline := enc.(ascii85Encoding)
+	return &bytesEncodedValue{dst: p, enc: enc, typ: typ, inline: inline}
+}
+
+// BytesEncoded creates and returns a new flag.Value compliant bytes parser
+// decoding values with enc, for codecs this package does not provide.
+func BytesEncoded(p *[]byte, value []byte, enc Encoding) flag.Value {
+	return newBytesEncoded(p, value, enc, TypeBytesEncoded)
+}
+
+// BytesBase64URL creates and returns a new flag.Value compliant URL-safe
+// base64 bytes parser, as defined in RFC 4648.
+func BytesBase64URL(p *[]byte, value []byte) flag.Value {
+	return newBytesEncoded(p, value, base64.URLEncoding, TypeBytesBase64URL)
+}
+
+// BytesBase64RawURL creates and returns a new flag.Value compliant unpadded
+// URL-safe base64 bytes parser, as used by JWTs.
+func BytesBase64RawURL(p *[]byte, value []byte) flag.Value {
+	return newBytesEncoded(p, value, base64.RawURLEncoding, TypeBytesBase64RawURL)
+}
+
+// BytesBase64Raw creates and returns a new flag.Value compliant unpadded
+// standard base64 bytes parser.
+func BytesBase64Raw(p *[]byte, value []byte) flag.Value {
+	return newBytesEncoded(p, value, base64.RawStdEncoding, TypeBytesBase64Raw)
+}
+
+// BytesBase32 creates and returns a new flag.Value compliant standard base32
+// bytes parser, as defined in RFC 4648.
+func BytesBase32(p *[]byte, value []byte) flag.Value {
+	return newBytesEncoded(p, value, base32.StdEncoding, TypeBytesBase32)
+}
+
+// BytesBase32Raw creates and returns a new flag.Value compliant unpadded
+// standard base32 bytes parser, as used by TOTP seeds.
+func BytesBase32Raw(p *[]byte, value []byte) flag.Value {
+	return newBytesEncoded(p, value, base32.StdEncoding.WithPadding(base32.NoPadding), TypeBytesBase32Raw)
+}
+
+// BytesBase32Hex creates and returns a new flag.Value compliant extended hex
+// base32 bytes parser, as defined in RFC 4648.
+func BytesBase32Hex(p *[]byte, value []byte) flag.Value {
+	return newBytesEncoded(p, value, base32.HexEncoding, TypeBytesBase32Hex)
+}
+
+// BytesBase32HexRaw creates and returns a new flag.Value compliant unpadded
+// extended hex base32 bytes parser.
+func BytesBase32HexRaw(p *[]byte, value []byte) flag.Value {
+	return newBytesEncoded(p, value, base32.HexEncoding.WithPadding(base32.NoPadding), TypeBytesBase32HexRaw)
+}
+
+// BytesHexFormatted creates and returns a new flag.Value compliant hex bytes
+// parser accepting separated bytes and an optional 0x prefix, rendering them
+// in the given style.
+func BytesHexFormatted(p *[]byte, value []byte, style HexStyle) flag.Value {
+	return newBytesEncoded(p, value, style, TypeBytesHexFormatted)
+}
+
+// BytesBase58 creates and returns a new flag.Value compliant base58 bytes
+// parser, using the Bitcoin alphabet.
+func BytesBase58(p *[]byte, value []byte) flag.Value {
+	return newBytesEncoded(p, value, Base58, TypeBytesBase58)
+}
+
+// BytesAscii85 creates and returns a new flag.Value compliant ascii85 bytes
+// parser. Values may be enclosed in <~ and ~> delimiters. They are always
+// taken inline, never as sources, since the ascii85 alphabet holds @, : and
+// -, so that @"84W is the encoding of 60800000 rather than a file path.
+func BytesAscii85(p *[]byte, value []byte) flag.Value {
+	return newBytesEncoded(p, value, Ascii85, TypeBytesAscii85)
+}
+
+// Hex is the hexadecimal encoding, rendered in upper case as BytesHex does.
+var Hex Encoding = hexEncoding{}
+
+// hexEncoding adapts encoding/hex to Encoding.
+type hexEncoding struct{}
+
+// EncodeToString implements Encoding.EncodeToString.
+func (hexEncoding) EncodeToString(src []byte) string {
+	return strings.ToUpper(hex.EncodeToString(src))
+}
+
+// DecodeString implements Encoding.DecodeString.
+func (hexEncoding) DecodeString(s string) ([]byte, error) {
+	return hex.DecodeString(s)
+}
+
+// HexStyle is a hexadecimal encoding rendering bytes in upper or lower case,
+// optionally separated. Decoding is lenient: an optional 0x prefix and colon,
+// space or dash separators between bytes are accepted whatever the style, as
+// in the fingerprints and serial numbers shown by openssl and browsers.
+type HexStyle struct {
+	// Upper renders digits in upper case.
+	Upper bool
+	// Separator is written between bytes.
+	Separator string
+}
+
+// Common hexadecimal styles.
+var (
+	// HexUpperColon renders AA:BB:CC, as openssl does for fingerprints.
+	HexUpperColon = HexStyle{Upper: true, Separator: ":"}
+	// HexLowerColon renders aa:bb:cc.
+	HexLowerColon = HexStyle{Separator: ":"}
+	// HexUpper renders AABBCC.
+	HexUpper = HexStyle{Upper: true}
+	// HexLower renders aabbcc.
+	HexLower = HexStyle{}
+)
+
+// EncodeToString implements Encoding.EncodeToString.
+func (s HexStyle) EncodeToString(src []byte) string {
+	digits := "0123456789abcdef"
+	if s.Upper {
+		digits = "0123456789ABCDEF"
+	}
+	var sb strings.Builder
+	for i, b := range src {
+		if i > 0 {
+			sb.WriteString(s.Separator)
+		}
+		sb.WriteByte(digits[b>>4])
+		sb.WriteByte(digits[b&0x0f])
+	}
+	return sb.String()
+}
+
+// DecodeString implements Encoding.DecodeString.
+func (HexStyle) DecodeString(s string) ([]byte, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+	}
+	if !strings.ContainsAny(s, ": -") {
+		return hex.DecodeString(s)
+	}
+
+	// Separated bytes may omit their leading zero, as in 1:a:ff.
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ':' || r == ' ' || r == '-'
+	})
+	data := make([]byte, 0, len(fields))
+	for _, field := range fields {
+		if len(field) == 1 {
+			field = "0" + field
+		}
+		b, err := hex.DecodeString(field)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != 1 {
+			return nil, fmt.Errorf("invalid hex byte %q", field)
+		}
+		data = append(data, b[0])
+	}
+	return data, nil
+}
+
+// Base58 is the base58 encoding using the Bitcoin alphabet.
+var Base58 Encoding = base58Encoding{}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encoding implements Encoding for base58, leading zero bytes being
+// encoded as leading ones.
+type base58Encoding struct{}
+
+// EncodeToString implements Encoding.EncodeToString.
+func (base58Encoding) EncodeToString(src []byte) string {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+
+	// Digits in base 58, least significant first.
+	var digits []byte
+	for _, b := range src[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+
+	var sb strings.Builder
+	sb.Grow(zeros + len(digits))
+	for i := 0; i < zeros; i++ {
+		sb.WriteByte(base58Alphabet[0])
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		sb.WriteByte(base58Alphabet[digits[i]])
+	}
+	return sb.String()
+}
+
+// DecodeString implements Encoding.DecodeString.
+func (base58Encoding) DecodeString(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	// Bytes, least significant first.
+	var data []byte
+	for i := zeros; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, fmt.Errorf("illegal base58 data at input byte %d", i)
+		}
+		for j := range data {
+			carry += int(data[j]) * 58
+			data[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			data = append(data, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	out := make([]byte, zeros+len(data))
+	for i, b := range data {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
+
+// Ascii85 is the ascii85 encoding, as used by PostScript and PDF. Values
+// decoded with it, through BytesAscii85 or BytesEncoded, are never resolved
+// as sources.
+var Ascii85 Encoding = ascii85Encoding{}
+
+// ascii85Encoding adapts encoding/ascii85 to Encoding.
+type ascii85Encoding struct{}
+
+// EncodeToString implements Encoding.EncodeToString.
+func (ascii85Encoding) EncodeToString(src []byte) string {
+	dst := make([]byte, ascii85.MaxEncodedLen(len(src)))
+	return string(dst[:ascii85.Encode(dst, src)])
+}
+
+// DecodeString implements Encoding.DecodeString.
+func (ascii85Encoding) DecodeString(s string) ([]byte, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "<~"), "~>")
+	dst := make([]byte, 4*len(s))
+	n, _, err := ascii85.Decode(dst, []byte(s), true)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n], nil
+}
@@ -0,0 +1,142 @@
+package flagvars
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"testing"
+)
+
+func TestBytesEncodings(t *testing.T) {
+	testCases := []struct {
+		newValue func(p *[]byte, value []byte) flag.Value
+		input    string
+		success  bool
+		expected string
+	}{
+		// Positive cases
+		{BytesBase64URL, "-_8=", true, "fbff"},
+		{BytesBase64RawURL, "-_8", true, "fbff"},
+		{BytesBase64Raw, "+/8", true, "fbff"},
+		{BytesBase32, "MZXW6===", true, "666f6f"},
+		{BytesBase32Raw, "MZXW6", true, "666f6f"},
+		{BytesBase32Hex, "CPNMU===", true, "666f6f"},
+		{BytesBase32HexRaw, "CPNMU", true, "666f6f"},
+		{BytesBase58, "1112", true, "00000001"},
+		{BytesBase58, "5Q", true, "ff"},
+		{BytesBase58, "StV1DL6CwTryKyV", true, "68656c6c6f20776f726c64"},
+		{BytesAscii85, "<~BOu!rD]j7BEbo7~>", true, "68656c6c6f20776f726c64"},
+		{BytesAscii85, "z", true, "00000000"},
+		{BytesAscii85, `@"84W`, true, "60800000"},
+
+		// Negative cases
+		{BytesBase64URL, "+/8=", false, ""},
+		{BytesBase64RawURL, "-_8=", false, ""},
+		{BytesBase32, "MZXW6", false, ""},
+		{BytesBase32Raw, "mzxw6!", false, ""},
+		{BytesBase58, "0OIl", false, ""},
+		{BytesAscii85, "{", false, ""},
+	}
+
+	for _, tc := range testCases {
+		var data []byte
+		v := tc.newValue(&data, nil)
+		err := v.Set(tc.input)
+		if err != nil && tc.success {
+			t.Errorf("expected success, got %q", err)
+			continue
+		} else if err == nil && !tc.success {
+			t.Errorf("expected failure while processing %q", tc.input)
+			continue
+		} else if tc.success && hex.EncodeToString(data) != tc.expected {
+			t.Errorf("got: %x, expected %s", data, tc.expected)
+		}
+	}
+}
+
+func TestBase58RoundTrip(t *testing.T) {
+	for _, data := range [][]byte{nil, {0}, {0, 0, 1}, {255, 255, 255, 255}, bytes.Repeat([]byte{7, 0}, 20)} {
+		decoded, err := Base58.DecodeString(Base58.EncodeToString(data))
+		if err != nil {
+			t.Fatalf("expected success, got %q", err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Fatalf("got: %x, expected %x", decoded, data)
+		}
+	}
+}
+
+type upperHex struct{}
+
+func (upperHex) EncodeToString(src []byte) string      { return hex.EncodeToString(src) + "!" }
+func (upperHex) DecodeString(s string) ([]byte, error) { return hex.DecodeString(s) }
+
+func TestBytesEncoded(t *testing.T) {
+	var data []byte
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	v := BytesEncoded(&data, []byte{1}, upperHex{})
+	fs.Var(v, "data", "data")
+	if f := fs.Lookup("data"); f.DefValue != "01!" {
+		t.Fatalf("got: %q, expected %q", f.DefValue, "01!")
+	}
+	if err := fs.Parse([]string{"-data", "0203"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(data, []byte{2, 3}) || v.String() != "0203!" || v.(flag.Getter).Get() == nil {
+		t.Fatalf("got: %X, %q, expected 0203", data, v.String())
+	}
+	if got := v.(interface{ Type() string }).Type(); got != TypeBytesEncoded {
+		t.Fatalf("got: %q, expected %q", got, TypeBytesEncoded)
+	}
+}
+
+func TestBytesEncodedVar(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	BytesBase64RawURLFlag(fs, "jwt-key", nil, "JWT key")
+	BytesBase32RawFlag(fs, "totp-seed", nil, "TOTP seed")
+	BytesBase58Flag(fs, "wallet-key", nil, "wallet key")
+	BytesAscii85Flag(fs, "blob", nil, "blob")
+	BytesEncodedFlag(fs, upperHex{}, "custom", nil, "custom")
+}
+
+func TestBytesHexFormatted(t *testing.T) {
+	testCases := []struct {
+		style    HexStyle
+		input    string
+		success  bool
+		expected string
+	}{
+		// Positive cases
+		{HexUpperColon, "aa:bb:cc", true, "AA:BB:CC"},
+		{HexUpperColon, "AA BB CC", true, "AA:BB:CC"},
+		{HexLower, "AA-BB-CC", true, "aabbcc"},
+		{HexLower, "0xAABBCC", true, "aabbcc"},
+		{HexLowerColon, "1:a:ff", true, "01:0a:ff"},
+		{HexUpper, "aabbcc", true, "AABBCC"},
+
+		// Negative cases
+		{HexUpperColon, "aab:bcc", false, ""},
+		{HexUpperColon, "zz:00", false, ""},
+		{HexUpperColon, "abc", false, ""},
+	}
+
+	for _, tc := range testCases {
+		var data []byte
+		v := BytesHexFormatted(&data, nil, tc.style)
+		err := v.Set(tc.input)
+		if err != nil && tc.success {
+			t.Errorf("expected success, got %q", err)
+			continue
+		} else if err == nil && !tc.success {
+			t.Errorf("expected failure while processing %q", tc.input)
+			continue
+		} else if tc.success && v.String() != tc.expected {
+			t.Errorf("got: %q, expected %q", v.String(), tc.expected)
+		}
+	}
+}
+
+func TestBytesHexFormattedVar(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	BytesHexFormattedFlag(fs, HexUpperColon, "pin", nil, "certificate pin")
+}
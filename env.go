@@ -0,0 +1,143 @@
+package flagvars
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Origin tells where the value of a flag came from.
+type Origin int
+
+const (
+	// OriginDefault means the flag kept its default value.
+	OriginDefault Origin = iota
+	// OriginEnv means the flag was set from an environment variable.
+	OriginEnv
+	// OriginCommandLine means the flag was set on the command line.
+	OriginCommandLine
+)
+
+// String implements fmt.Stringer.
+func (o Origin) String() string {
+	switch o {
+	case OriginDefault:
+		return "default"
+	case OriginEnv:
+		return "env"
+	case OriginCommandLine:
+		return "command line"
+	}
+	return fmt.Sprintf("Origin(%d)", int(o))
+}
+
+// envBinding records a flag set from the environment.
+type envBinding struct {
+	// variable is the name of the environment variable.
+	variable string
+	// beforeParse tells whether the flag was set before fs.Parse, in which
+	// case the command line may still override it.
+	beforeParse bool
+}
+
+// envBindings holds the flags set from the environment for each flag set.
+var envBindings = struct {
+	sync.Mutex
+	m map[*flag.FlagSet]map[string]envBinding
+}{m: make(map[*flag.FlagSet]map[string]envBinding)}
+
+// EnvName returns the environment variable BindEnv looks up for the flag
+// name: prefix and name joined by an underscore, uppercased, with dashes and
+// dots turned into underscores. PREFIX_TLS_CERT for tls-cert, for example.
+func EnvName(prefix, name string) string {
+	if prefix != "" {
+		name = prefix + "_" + name
+	}
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// BindEnv sets every flag of fs which was not set on the command line from
+// the environment variable named by EnvName, through the Set method of the
+// flag so that the value is parsed the same way in both places. The command
+// line takes precedence over the environment, which takes precedence over
+// defaults: when BindEnv is called before fs.Parse, values from the
+// environment become the defaults the command line may override. The
+// defaults printed by flag.PrintDefaults are left alone, so that keys taken
+// from the environment are never shown in usage messages. Origins reports
+// which of them set each flag.
+func BindEnv(fs *flag.FlagSet, prefix string) error {
+	var msgs []string
+	fs.VisitAll(func(f *flag.Flag) {
+		variable := EnvName(prefix, f.Name)
+		if err := bindEnv(fs, f, variable); err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	})
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// bindEnv sets f from the environment variable named variable, if it exists
+// and f was not set on the command line.
+func bindEnv(fs *flag.FlagSet, f *flag.Flag, variable string) error {
+	value, ok := os.LookupEnv(variable)
+	if !ok || isSet(fs, f.Name) {
+		return nil
+	}
+
+	if fs.Parsed() {
+		if err := fs.Set(f.Name, value); err != nil {
+			return fmt.Errorf("invalid value for flag -%s from %s: %v", f.Name, variable, err)
+		}
+	} else {
+		if err := f.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid value for flag -%s from %s: %v", f.Name, variable, err)
+		}
+	}
+
+	envBindings.Lock()
+	defer envBindings.Unlock()
+	if envBindings.m[fs] == nil {
+		envBindings.m[fs] = make(map[string]envBinding)
+	}
+	envBindings.m[fs][f.Name] = envBinding{variable: variable, beforeParse: !fs.Parsed()}
+	return nil
+}
+
+// isSet reports whether the name flag of fs has been set through fs.Parse or
+// fs.Set.
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// Origins reports where the value of each flag of fs came from.
+func Origins(fs *flag.FlagSet) map[string]Origin {
+	envBindings.Lock()
+	bindings := envBindings.m[fs]
+	envBindings.Unlock()
+
+	origins := make(map[string]Origin)
+	fs.VisitAll(func(f *flag.Flag) {
+		origins[f.Name] = OriginDefault
+	})
+	fs.Visit(func(f *flag.Flag) {
+		origins[f.Name] = OriginCommandLine
+	})
+	for name, b := range bindings {
+		if !b.beforeParse || origins[name] == OriginDefault {
+			origins[name] = OriginEnv
+		}
+	}
+	return origins
+}
@@ -0,0 +1,107 @@
+package flagvars
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	for _, tc := range []struct {
+		prefix, name, expected string
+	}{
+		{"APP", "tls-cert", "APP_TLS_CERT"},
+		{"", "tls.ca-cert", "TLS_CA_CERT"},
+	} {
+		if got := EnvName(tc.prefix, tc.name); got != tc.expected {
+			t.Errorf("got: %q, expected %q", got, tc.expected)
+		}
+	}
+}
+
+func TestBindEnv(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+	t.Setenv("APP_TLS_CERT", strings.ReplaceAll(certPEM, "\n", `\n`))
+	t.Setenv("APP_HMAC_KEY", "0102")
+	t.Setenv("APP_SEED", "0304")
+
+	var (
+		cert x509.Certificate
+		key  []byte
+		seed []byte
+		salt []byte
+	)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(Certificate(&cert), "tls-cert", "certificate")
+	fs.Var(BytesHex(&key, nil), "hmac-key", "HMAC key")
+	fs.Var(BytesHex(&seed, nil), "seed", "seed")
+	fs.Var(BytesHex(&salt, []byte{9}), "salt", "salt")
+
+	if err := BindEnv(fs, "APP"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := fs.Parse([]string{"-seed", "0506"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	if !cert.Equal(generated.Leaf) {
+		t.Fatalf("got: %v, expected %v", cert.Subject, generated.Leaf.Subject)
+	}
+	if !bytes.Equal(key, []byte{1, 2}) || !bytes.Equal(seed, []byte{5, 6}) || !bytes.Equal(salt, []byte{9}) {
+		t.Fatalf("got: %X, %X, %X, expected 0102, 0506, 09", key, seed, salt)
+	}
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	if strings.Contains(usage.String(), "0102") || strings.Contains(usage.String(), strings.Split(certPEM, "\n")[1]) {
+		t.Fatalf("got: %q, expected values from the environment not to be printed", usage.String())
+	}
+
+	expected := map[string]Origin{
+		"tls-cert": OriginEnv,
+		"hmac-key": OriginEnv,
+		"seed":     OriginCommandLine,
+		"salt":     OriginDefault,
+	}
+	origins := Origins(fs)
+	for name, origin := range expected {
+		if origins[name] != origin {
+			t.Errorf("got: %v, expected %v for flag -%s", origins[name], origin, name)
+		}
+	}
+}
+
+func TestBindEnvAfterParse(t *testing.T) {
+	t.Setenv("HMAC_KEY", "0102")
+	t.Setenv("SEED", "zz")
+
+	var key, seed []byte
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(BytesHex(&key, nil), "hmac-key", "HMAC key")
+	fs.Var(BytesHex(&seed, nil), "seed", "seed")
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	err := BindEnv(fs, "")
+	if err == nil || !strings.Contains(err.Error(), "flag -seed from SEED") {
+		t.Fatalf("got: %v, expected failure naming flag and variable", err)
+	}
+	if strings.Contains(err.Error(), "zz") {
+		t.Fatalf("got: %q, expected value not to be echoed", err)
+	}
+	if !bytes.Equal(key, []byte{1, 2}) {
+		t.Fatalf("got: %X, expected 0102", key)
+	}
+	if origin := Origins(fs)["hmac-key"]; origin != OriginEnv {
+		t.Fatalf("got: %v, expected %v", origin, OriginEnv)
+	}
+}
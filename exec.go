@@ -0,0 +1,116 @@
+package flagvars
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ExecResolver resolves references by running a credential helper, such as
+// pass or op, and taking its standard output. Commands run directly from
+// their argv, never through a shell. As running commands from flag values is
+// a deliberate choice, it is not registered by default, and only the
+// commands it is configured with are run:
+//
+//	flagvars.RegisterResolver("exec", &flagvars.ExecResolver{
+//		Commands: map[string][]string{"app-key": {"pass", "show", "app/key"}},
+//	})
+//
+// after which exec:app-key runs pass with the arguments show and app/key.
+type ExecResolver struct {
+	// Commands holds the commands the resolver runs, by name. References
+	// are names looked up in Commands.
+	Commands map[string][]string
+
+	// AllowAnyCommand makes the resolver take references not found in
+	// Commands as the argv of the command to run, split on white space, so
+	// that arguments cannot contain spaces.
+	//
+	// WARNING: flag values also come from environment variables bound with
+	// BindEnv and from configuration files, so whoever controls those can
+	// then run any command with the privileges of the process.
+	AllowAnyCommand bool
+
+	// Timeout bounds the execution of a command. Defaults to
+	// ResolveTimeout, which already bounds the resolution of flag values, so
+	// that Timeout can only shorten it: commands which may take longer, such
+	// as interactive ones, need ResolveTimeout to be raised too.
+	Timeout time.Duration
+
+	// MaxOutput bounds the size of the standard output of a command.
+	// Defaults to 1 MiB.
+	MaxOutput int
+}
+
+// maxExecStderr bounds how much of the standard error of a failing command
+// is reported.
+const maxExecStderr = 4 << 10
+
+// Resolve implements Resolver.Resolve.
+func (r *ExecResolver) Resolve(ctx context.Context, ref string) ([]byte, error) {
+	argv, ok := r.Commands[ref]
+	if !ok {
+		if !r.AllowAnyCommand {
+			return nil, fmt.Errorf("command %q is not configured", ref)
+		}
+		argv = strings.Fields(ref)
+	}
+	if len(argv) == 0 {
+		return nil, errors.New("missing command")
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = ResolveTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	maxOutput := r.MaxOutput
+	if maxOutput <= 0 {
+		maxOutput = 1 << 20
+	}
+	stdout := &limitedBuffer{max: maxOutput}
+	stderr := &limitedBuffer{max: maxExecStderr}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s: %v", argv[0], ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.buf.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %v: %s", argv[0], err, msg)
+		}
+		return nil, fmt.Errorf("%s: %v", argv[0], err)
+	}
+	if stdout.overflow {
+		return nil, fmt.Errorf("%s: output exceeds %d bytes", argv[0], maxOutput)
+	}
+
+	return stdout.buf.Bytes(), nil
+}
+
+// limitedBuffer is a buffer which silently drops whatever is written past
+// max bytes, so that commands are never blocked on a full pipe.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	max      int
+	overflow bool
+}
+
+// Write implements io.Writer.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.max - b.buf.Len(); len(p) > room {
+		p = p[:room]
+		b.overflow = true
+	}
+	b.buf.Write(p)
+	return n, nil
+}
@@ -0,0 +1,66 @@
+package flagvars
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecResolver(t *testing.T) {
+	RegisterResolver("exec", &ExecResolver{AllowAnyCommand: true})
+	defer RegisterResolver("exec", nil)
+
+	var b []byte
+	hexValue := BytesHex(&b, nil)
+	if err := hexValue.Set("exec:echo 0102"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(b, []byte{1, 2}) || hexValue.String() != "exec:echo 0102" {
+		t.Fatalf("got: %X from %q, expected 0102", b, hexValue.String())
+	}
+
+	// No shell is involved, so the expansion reaches echo verbatim.
+	var s []byte
+	if err := BytesFile(&s, "").Set("exec:echo $HOME;"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if string(s) != "$HOME;\n" {
+		t.Fatalf("got: %q, expected %q", s, "$HOME;\n")
+	}
+}
+
+func TestExecResolverFailures(t *testing.T) {
+	testCases := []struct {
+		resolver *ExecResolver
+		ref      string
+		expected string
+	}{
+		{&ExecResolver{AllowAnyCommand: true}, "sh -c exit\\ 3", "exit status"},
+		{&ExecResolver{AllowAnyCommand: true}, "", "missing command"},
+		{&ExecResolver{AllowAnyCommand: true}, "ls /flagvars-missing-directory", "flagvars-missing-directory"},
+		{&ExecResolver{AllowAnyCommand: true, MaxOutput: 4}, "echo 0123456789", "output exceeds 4 bytes"},
+		{&ExecResolver{AllowAnyCommand: true, Timeout: 10 * time.Millisecond}, "sleep 5", "deadline exceeded"},
+		{&ExecResolver{Commands: map[string][]string{"key": {"echo", "key"}}}, "echo secret", "not configured"},
+		{&ExecResolver{}, "echo secret", "not configured"},
+	}
+
+	for _, tc := range testCases {
+		_, err := tc.resolver.Resolve(context.Background(), tc.ref)
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("got: %v, expected error containing %q while processing %q", err, tc.expected, tc.ref)
+		}
+	}
+}
+
+func TestExecResolverCommands(t *testing.T) {
+	r := &ExecResolver{Commands: map[string][]string{"key": {"echo", "-n", "secret"}}}
+	data, err := r.Resolve(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if string(data) != "secret" {
+		t.Fatalf("got: %q, expected %q", data, "secret")
+	}
+}
@@ -0,0 +1,106 @@
+package flagvars
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Format selects how values render themselves through String.
+type Format int
+
+const (
+	// FormatPEM renders certificates PEM encoded, which round-trips through
+	// Set.
+	FormatPEM Format = iota
+	// FormatSummary renders a readable description of each certificate:
+	// subject, issuer, SANs, serial, validity, key and SHA-256 fingerprint.
+	// Private keys and TLS certificates are described by their algorithm and
+	// the fingerprint of their public part, never by their secret material.
+	FormatSummary
+)
+
+// StringFormat selects how certificate and key values render themselves
+// through String, as shown by flag.PrintDefaults or when logging the
+// effective configuration. Defaults to FormatPEM, under which private keys
+// and TLS certificates render as "<redacted>".
+var StringFormat = FormatPEM
+
+// fingerprint returns the SHA-256 digest of data as colon separated
+// uppercase hex, the way openssl displays fingerprints.
+func fingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return HexUpperColon.EncodeToString(sum[:])
+}
+
+// describePublicKey returns the algorithm and size or curve of a public key.
+func describePublicKey(pub crypto.PublicKey) string {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", pub.N.BitLen())
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("ECDSA %s", pub.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return fmt.Sprintf("%T", pub)
+}
+
+// summarizePublicKey returns a single line readable description of pub
+// which is safe to log in place of its private counterpart.
+func summarizePublicKey(pub crypto.PublicKey) string {
+	return fmt.Sprintf("key=%q sha256=%s", describePublicKey(pub), publicKeyFingerprint(pub))
+}
+
+// publicKeyFingerprint returns the SHA-256 fingerprint of the PKIX encoding
+// of pub.
+func publicKeyFingerprint(pub crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "unknown"
+	}
+	return fingerprint(der)
+}
+
+// summarizeCertificate returns a single line readable description of c.
+func summarizeCertificate(c *x509.Certificate) string {
+	var sans []string
+	sans = append(sans, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, c.EmailAddresses...)
+	for _, uri := range c.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	return fmt.Sprintf("subject=%q issuer=%q sans=[%s] serial=%s notBefore=%s notAfter=%s key=%q sha256=%s",
+		c.Subject.String(),
+		c.Issuer.String(),
+		strings.Join(sans, " "),
+		c.SerialNumber.Text(16),
+		c.NotBefore.UTC().Format(time.RFC3339),
+		c.NotAfter.UTC().Format(time.RFC3339),
+		describePublicKey(c.PublicKey),
+		fingerprint(c.Raw),
+	)
+}
+
+// describeSubject renders a DER encoded subject as a distinguished name.
+func describeSubject(der []byte) string {
+	var rdn pkix.RDNSequence
+	if _, err := asn1.Unmarshal(der, &rdn); err != nil {
+		return fmt.Sprintf("%X", der)
+	}
+	var name pkix.Name
+	name.FillFromRDNSequence(&rdn)
+	return name.String()
+}
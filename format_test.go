@@ -0,0 +1,163 @@
+package flagvars
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func withStringFormat(t *testing.T, f Format) {
+	previous := StringFormat
+	StringFormat = f
+	t.Cleanup(func() { StringFormat = previous })
+}
+
+func TestCertificateSummary(t *testing.T) {
+	withStringFormat(t, FormatSummary)
+
+	generated, err := generateSelfSigned([]string{"localhost", "127.0.0.1"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+
+	var cert x509.Certificate
+	certValue := Certificate(&cert)
+	if err := certValue.Set(certPEM); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	sum := sha256.Sum256(cert.Raw)
+	for _, expected := range []string{
+		`subject="CN=localhost"`,
+		`issuer="CN=localhost"`,
+		`sans=[localhost 127.0.0.1]`,
+		`serial=` + cert.SerialNumber.Text(16),
+		`key="ECDSA P-256"`,
+		fmt.Sprintf("sha256=%02X:%02X", sum[0], sum[1]),
+	} {
+		if got := certValue.String(); !strings.Contains(got, expected) {
+			t.Fatalf("got: %q, expected to contain %q", got, expected)
+		}
+	}
+	if strings.Contains(certValue.String(), "BEGIN CERTIFICATE") {
+		t.Fatalf("got: %q, expected no PEM", certValue.String())
+	}
+
+	var certs []*x509.Certificate
+	certsValue := Certificates(&certs)
+	if err := certsValue.Set(certPEM + certPEM); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if lines := strings.Count(certsValue.String(), "\n"); lines != 2 {
+		t.Fatalf("got: %d, expected %d", lines, 2)
+	}
+
+	var pool x509.CertPool
+	poolValue := CertPool(&pool)
+	if err := poolValue.Set(certPEM); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if got := poolValue.String(); got != certValue.String()+"\n" {
+		t.Fatalf("got: %q, expected %q", got, certValue.String()+"\n")
+	}
+}
+
+func TestCertificatePEMFormat(t *testing.T) {
+	withStringFormat(t, FormatPEM)
+
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+
+	var pool x509.CertPool
+	poolValue := CertPool(&pool)
+	if err := poolValue.Set(certPEM); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	var roundTrip x509.CertPool
+	if err := CertPool(&roundTrip).Set(poolValue.String()); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !pool.Equal(&roundTrip) {
+		t.Fatalf("expected pool to round-trip")
+	}
+
+	pool = *x509.NewCertPool()
+	pool.AddCert(generated.Leaf)
+	if got := (certPoolValue{dst: &pool}).String(); got != "CN=localhost\n" {
+		t.Fatalf("got: %q, expected %q", got, "CN=localhost\n")
+	}
+}
+
+func TestPrivateKeySummary(t *testing.T) {
+	withStringFormat(t, FormatSummary)
+
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{KeyType: RSA2048})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	key := generated.PrivateKey.(*rsa.PrivateKey)
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	var priv rsa.PrivateKey
+	privValue := RSAPrivateKey(&priv)
+	if got := privValue.String(); got != "" {
+		t.Fatalf("got: %q, expected %q", got, "")
+	}
+	if err := privValue.Set(keyPEM); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	pubDer, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	expected := fmt.Sprintf("key=%q sha256=%s", "RSA 2048", fingerprint(pubDer))
+	if got := privValue.String(); got != expected {
+		t.Fatalf("got: %q, expected %q", got, expected)
+	}
+
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+	var tlsCert tls.Certificate
+	tlsValue := TLSCertificate(&tlsCert)
+	if err := tlsValue.Set(keyPEM + certPEM); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	expected = fmt.Sprintf("subject=%q sha256=%s", "CN=localhost", fingerprint(generated.Leaf.Raw))
+	if got := tlsValue.String(); got != expected {
+		t.Fatalf("got: %q, expected %q", got, expected)
+	}
+	if strings.Contains(tlsValue.String(), "PRIVATE") {
+		t.Fatalf("got: %q, expected no secret material", tlsValue.String())
+	}
+}
+
+func TestECDSAPrivateKeySummary(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{KeyType: ECDSAP384})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	der, _ := x509.MarshalECPrivateKey(generated.PrivateKey.(*ecdsa.PrivateKey))
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+
+	var priv ecdsa.PrivateKey
+	privValue := ECDSAPrivateKey(&priv)
+	if err := privValue.Set(keyPEM); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if got := privValue.String(); got != "<redacted>" {
+		t.Fatalf("got: %q, expected %q", got, "<redacted>")
+	}
+
+	withStringFormat(t, FormatSummary)
+	if got := privValue.String(); !strings.HasPrefix(got, `key="ECDSA P-384" sha256=`) {
+		t.Fatalf("got: %q, expected %q prefix", got, `key="ECDSA P-384" sha256=`)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/glerchundi/flagvars
 
-go 1.12
+go 1.18
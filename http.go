@@ -0,0 +1,120 @@
+package flagvars
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// HTTPResolver resolves references by fetching them over HTTP. It handles
+// the https scheme by default, using http.DefaultClient. Responses are
+// cached and revalidated through ETag and Last-Modified, which keeps
+// periodic refreshes cheap.
+type HTTPResolver struct {
+	// Client is used to perform requests. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// MaxBodySize bounds the size of a response body. Defaults to 1 MiB.
+	MaxBodySize int64
+
+	// Scheme is prepended to references to build the requested URL.
+	// Defaults to https.
+	Scheme string
+
+	mu    sync.Mutex
+	cache map[string]httpCacheEntry
+}
+
+// httpCacheEntry is a previously fetched response body along with its
+// validators.
+type httpCacheEntry struct {
+	etag         string
+	lastModified string
+	data         []byte
+}
+
+// NewHTTPClient returns an http.Client trusting the certificates of pool,
+// as parsed by CertPool, to be used with an HTTPResolver.
+func NewHTTPClient(pool *x509.CertPool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http.Client{Transport: transport}
+}
+
+// Resolve implements Resolver.Resolve.
+func (r *HTTPResolver) Resolve(ctx context.Context, ref string) ([]byte, error) {
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	url := scheme + ":" + ref
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	cached, ok := r.cache[url]
+	r.mu.Unlock()
+	if ok {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && ok {
+		return cached.data, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	maxBodySize := r.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = 1 << 20
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBodySize {
+		return nil, fmt.Errorf("GET %s: body exceeds %d bytes", url, maxBodySize)
+	}
+
+	entry := httpCacheEntry{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		data:         data,
+	}
+	r.mu.Lock()
+	if entry.etag != "" || entry.lastModified != "" {
+		if r.cache == nil {
+			r.cache = make(map[string]httpCacheEntry)
+		}
+		r.cache[url] = entry
+	} else {
+		delete(r.cache, url)
+	}
+	r.mu.Unlock()
+
+	return data, nil
+}
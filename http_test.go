@@ -0,0 +1,237 @@
+package flagvars
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testDocument is served by newTestServer with an ETag derived from its
+// version.
+type testDocument struct {
+	mu       sync.Mutex
+	body     string
+	version  int
+	requests int
+	fetches  int
+}
+
+func (d *testDocument) update(body string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.body = body
+	d.version++
+}
+
+func (d *testDocument) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests++
+	etag := fmt.Sprintf(`"v%d"`, d.version)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	d.fetches++
+	w.Header().Set("ETag", etag)
+	fmt.Fprint(w, d.body)
+}
+
+func newTestServer(t *testing.T, doc *testDocument) (*httptest.Server, *HTTPResolver) {
+	server := httptest.NewTLSServer(doc)
+	t.Cleanup(server.Close)
+
+	var pool x509.CertPool
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := CertPool(&pool).Set(string(certPEM)); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	r := &HTTPResolver{Client: NewHTTPClient(&pool)}
+	RegisterResolver("https", r)
+	t.Cleanup(func() { RegisterResolver("https", &HTTPResolver{}) })
+	return server, r
+}
+
+func TestHTTPResolver(t *testing.T) {
+	doc := &testDocument{body: "0102"}
+	server, _ := newTestServer(t, doc)
+
+	var b []byte
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(BytesHex(&b, nil), "key", "key")
+	if err := fs.Parse([]string{"-key", server.URL + "/key"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(b, []byte{1, 2}) {
+		t.Fatalf("got: %X, expected 0102", b)
+	}
+	if got := fs.Lookup("key").Value.String(); got != server.URL+"/key" {
+		t.Fatalf("got: %q, expected %q", got, server.URL+"/key")
+	}
+
+	if err := Reload(fs); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if doc.requests != 2 || doc.fetches != 1 {
+		t.Fatalf("got: %d requests and %d fetches, expected 2 and 1", doc.requests, doc.fetches)
+	}
+
+	doc.update("0304")
+	if err := Reload(fs); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(b, []byte{3, 4}) {
+		t.Fatalf("got: %X, expected 0304", b)
+	}
+}
+
+func TestHTTPResolverLastModified(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		body     = "0102"
+		modified = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		fetches  int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("expected no ETag to be sent")
+		}
+		if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fetches++
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	r := &HTTPResolver{Scheme: "http"}
+	ref := strings.TrimPrefix(server.URL, "http:")
+	for i := 0; i < 2; i++ {
+		data, err := r.Resolve(context.Background(), ref)
+		if err != nil {
+			t.Fatalf("expected success, got %q", err)
+		}
+		if string(data) != "0102" {
+			t.Fatalf("got: %q, expected %q", data, "0102")
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("got: %d fetches, expected unchanged document to be revalidated", fetches)
+	}
+
+	mu.Lock()
+	body, modified = "0304", modified.Add(time.Hour)
+	mu.Unlock()
+	data, err := r.Resolve(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if string(data) != "0304" || fetches != 2 {
+		t.Fatalf("got: %q after %d fetches, expected %q after 2", data, fetches, "0304")
+	}
+}
+
+func TestHTTPResolverFailures(t *testing.T) {
+	doc := &testDocument{body: strings.Repeat("00", 64)}
+	server, r := newTestServer(t, doc)
+	r.MaxBodySize = 16
+
+	var b []byte
+	if err := BytesHex(&b, nil).Set(server.URL); err == nil || !strings.Contains(err.Error(), "exceeds 16 bytes") {
+		t.Fatalf("got: %v, expected body size failure", err)
+	}
+
+	notFound := httptest.NewTLSServer(http.NotFoundHandler())
+	defer notFound.Close()
+	if err := BytesHex(&b, nil).Set(notFound.URL); err == nil {
+		t.Fatalf("expected failure with untrusted server")
+	}
+	r.Client = notFound.Client()
+	if err := BytesHex(&b, nil).Set(notFound.URL); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("got: %v, expected not found failure", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	doc := &testDocument{body: "01"}
+	server, _ := newTestServer(t, doc)
+
+	first, err := generateSelfSigned([]string{"first"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	dir := t.TempDir()
+	writeKubernetesSecret(t, dir, "..2024_01_01_00_00_00.1", first)
+
+	var (
+		b      []byte
+		lazy   LazyFile
+		secret KubernetesTLSSecret
+	)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(BytesHex(&b, nil), "key", "key")
+	fs.Var(BytesFileLazy(&lazy, "", BytesFileOptions{}), "lazy", "lazy key")
+	fs.Var(KubernetesTLSSecretDir(&secret), "tls-secret-dir", "TLS secret directory")
+	if err := fs.Parse([]string{"-key", server.URL, "-lazy", server.URL, "-tls-secret-dir", dir}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if data, err := lazy.Bytes(); err != nil || string(data) != "01" {
+		t.Fatalf("got: %q, %v, expected 01", data, err)
+	}
+
+	doc.update("02")
+	if err := os.Remove(filepath.Join(dir, "..2024_01_01_00_00_00.1", kubernetesTLSCert)); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	errs := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go Refresh(ctx, fs, time.Millisecond, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	defer cancel()
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "flag -tls-secret-dir") || strings.Contains(err.Error(), "flag -key") {
+			t.Fatalf("got: %q, expected only the failing flag to be named", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected refresh failure to be reported")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if data, _ := lazy.Bytes(); string(data) == "02" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected lazy file to be refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if secret.Certificate() == nil {
+		t.Fatalf("expected failing refresh to keep the key pair")
+	}
+	if !bytes.Equal(b, []byte{1}) {
+		t.Fatalf("got: %X, expected values unsafe to refresh to keep 01", b)
+	}
+}
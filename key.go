@@ -0,0 +1,108 @@
+package flagvars
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"strings"
+)
+
+// parsePrivateKey parses a PKCS #1, SEC 1 or PKCS #8 encoded private key out
+// of a PEM block.
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return nil, errors.New("failed to find a suitable pem block type")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unknown type of private key")
+	}
+	return signer, nil
+}
+
+// publicKeysEqual reports whether both public keys are the same.
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
+}
+
+// privateKeyValue adapts crypto.Signer for use as a flag. Value of flag is
+// PEM encoded and may hold any RSA, ECDSA or Ed25519 private key.
+type privateKeyValue struct {
+	dst    *crypto.Signer
+	source string
+}
+
+// String implements flag.Value.String.
+func (v privateKeyValue) String() string {
+	if StringFormat == FormatSummary {
+		if v.dst == nil || *v.dst == nil {
+			return ""
+		}
+		return summarizePublicKey((*v.dst).Public())
+	}
+	if v.source != "" {
+		return v.source
+	}
+	return "<redacted>"
+}
+
+// Set implements flag.Value.Set.
+func (v *privateKeyValue) Set(value string) error {
+	data, source, err := resolvePrivate(value, 0)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	priv, err := parsePrivateKey(block)
+	if err != nil {
+		return err
+	}
+	*v.dst = priv
+	v.source = source
+
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (*privateKeyValue) Type() string {
+	return TypePrivateKey
+}
+
+// Get implements flag.Getter. It returns a crypto.Signer.
+func (v privateKeyValue) Get() interface{} {
+	return *v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *privateKeyValue) sourceRef() string {
+	return v.source
+}
+
+// publicKey implements publicKeyHolder.
+func (v *privateKeyValue) publicKey() crypto.PublicKey {
+	if *v.dst == nil {
+		return nil
+	}
+	return (*v.dst).Public()
+}
+
+// PrivateKey creates and returns a new flag.Value compliant PKCS #1, SEC 1
+// and PKCS #8 PrivateKey parser.
+func PrivateKey(p *crypto.Signer) flag.Value {
+	return &privateKeyValue{dst: p}
+}
@@ -0,0 +1,55 @@
+package flagvars
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"testing"
+)
+
+func TestPrivateKey(t *testing.T) {
+	rsaKey, err := generateKey(RSA2048)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	ecKey, err := generateKey(ECDSAP256)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	sec1, _ := x509.MarshalECPrivateKey(ecKey.(*ecdsa.PrivateKey))
+
+	testCases := []struct {
+		block    *pem.Block
+		expected crypto.Signer
+	}{
+		{&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey.(*rsa.PrivateKey))}, rsaKey},
+		{&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}, ecKey},
+		{&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}, ecKey},
+	}
+
+	for _, tc := range testCases {
+		var gotPriv crypto.Signer
+		privKeyValue := PrivateKey(&gotPriv)
+		if err := privKeyValue.Set(string(pem.EncodeToMemory(tc.block))); err != nil {
+			t.Fatalf("expected success, got %q", err)
+		}
+		if !publicKeysEqual(gotPriv.Public(), tc.expected.Public()) {
+			t.Fatalf("got: %v, expected %v", gotPriv, tc.expected)
+		}
+	}
+
+	var gotPriv crypto.Signer
+	if err := PrivateKey(&gotPriv).Set("-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----"); err == nil {
+		t.Fatalf("expected failure with certificate block")
+	}
+}
+
+func TestPrivateKeyVar(t *testing.T) {
+	var priv crypto.Signer
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Var(PrivateKey(&priv), "private-key", "private key")
+}
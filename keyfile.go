@@ -0,0 +1,22 @@
+package flagvars
+
+// FilePermissions configures the checks made on files holding private
+// material: the content of byte values, such as BytesFile, BytesHex or
+// Bytes, and of secret values, and private keys, TLS certificates and CAs
+// read through the file scheme or @path.
+type FilePermissions struct {
+	// Disabled turns the checks off. It is meant for environments where the
+	// mode of files is fixed, such as Kubernetes secret mounts.
+	Disabled bool
+	// Owner, if not nil, is the user id files must be owned by, typically
+	// os.Geteuid().
+	Owner *int
+}
+
+// KeyFilePermissions holds the checks made on files holding private
+// material. Like OpenSSH, files whose mode is other than 0600 or 0400 are
+// refused on Unix systems; there are no checks elsewhere. It can be disabled
+// from the command line with:
+//
+//	flag.BoolVar(&flagvars.KeyFilePermissions.Disabled, "insecure-key-files", false, "skip key file permission checks")
+var KeyFilePermissions FilePermissions
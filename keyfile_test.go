@@ -0,0 +1,97 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package flagvars
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKeyFile(t *testing.T, data []byte, mode os.FileMode) string {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := ioutil.WriteFile(path, data, mode); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	return path
+}
+
+func TestKeyFilePermissions(t *testing.T) {
+	defer func() { KeyFilePermissions = FilePermissions{} }()
+	uid, other := os.Geteuid(), os.Geteuid()+1
+
+	testCases := []struct {
+		mode     os.FileMode
+		perms    FilePermissions
+		expected string
+	}{
+		// Positive cases
+		{0600, FilePermissions{}, ""},
+		{0400, FilePermissions{}, ""},
+		{0644, FilePermissions{Disabled: true}, ""},
+		{0600, FilePermissions{Owner: &uid}, ""},
+
+		// Negative cases
+		{0644, FilePermissions{}, "has mode 0644, expected 0600 or 0400"},
+		{0640, FilePermissions{}, "has mode 0640, expected 0600 or 0400"},
+		{0700, FilePermissions{}, "has mode 0700, expected 0600 or 0400"},
+		{0600, FilePermissions{Owner: &other}, fmt.Sprintf("is owned by uid %d, expected %d", uid, other)},
+	}
+	for _, tc := range testCases {
+		KeyFilePermissions = tc.perms
+		path := writeKeyFile(t, []byte("0102"), tc.mode)
+
+		var b []byte
+		var s Secret
+		values := []struct {
+			value flag.Value
+			input string
+		}{
+			{BytesFile(&b, ""), path},
+			{BytesHex(&b, nil), "@" + path},
+			{BytesBase64(&b, nil), "file:" + path},
+			{Bytes(&b, nil, nil), "hex:@" + path},
+			{SecretBytesFile(&s), path},
+			{SecretBytesHex(&s), "@" + path},
+		}
+		for _, v := range values {
+			err := v.value.Set(v.input)
+			if tc.expected == "" && err != nil {
+				t.Fatalf("expected success, got %q", err)
+			}
+			if tc.expected != "" && (err == nil || !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), tc.expected)) {
+				t.Fatalf("got: %v, expected error containing %q", err, tc.expected)
+			}
+		}
+	}
+}
+
+func TestKeyFilePermissionsPrivateKey(t *testing.T) {
+	key, err := generateKey(ECDSAP256)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	path := writeKeyFile(t, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0644)
+
+	var priv crypto.Signer
+	if err := PrivateKey(&priv).Set("@" + path); err == nil {
+		t.Fatalf("expected failure with group and world readable key")
+	}
+
+	KeyFilePermissions.Disabled = true
+	defer func() { KeyFilePermissions.Disabled = false }()
+	if err := PrivateKey(&priv).Set("file:" + path); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package flagvars
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkKeyFile refuses the key file name, described by fi, unless its mode is
+// 0600 or 0400 and, if required by KeyFilePermissions, it is owned by the
+// expected user.
+func checkKeyFile(name string, fi os.FileInfo) error {
+	if KeyFilePermissions.Disabled {
+		return nil
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 && perm != 0400 {
+		return fmt.Errorf("%s has mode %04o, expected 0600 or 0400", name, perm)
+	}
+	if owner := KeyFilePermissions.Owner; owner != nil {
+		st, ok := fi.Sys().(*syscall.Stat_t)
+		if !ok {
+			return fmt.Errorf("%s: failed to find its owner", name)
+		}
+		if int(st.Uid) != *owner {
+			return fmt.Errorf("%s is owned by uid %d, expected %d", name, st.Uid, *owner)
+		}
+	}
+	return nil
+}
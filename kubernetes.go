@@ -0,0 +1,197 @@
+package flagvars
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Names of the entries of a mounted kubernetes.io/tls secret. The kubelet
+// updates mounted secrets by writing a new timestamped directory and
+// atomically swapping the ..data symlink to it; the other entries are
+// symlinks through ..data.
+const (
+	kubernetesDataDir = "..data"
+	kubernetesTLSCert = "tls.crt"
+	kubernetesTLSKey  = "tls.key"
+	kubernetesCACert  = "ca.crt"
+)
+
+// KubernetesTLSSecret holds the key pair and, when present, the CA
+// certificates of a kubernetes.io/tls secret mounted as a directory. It is
+// safe for concurrent use, so that it can be reloaded while serving.
+type KubernetesTLSSecret struct {
+	mu      sync.RWMutex
+	dir     string
+	version string
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+}
+
+// Certificate returns the key pair read from tls.key and tls.crt.
+func (s *KubernetesTLSSecret) Certificate() *tls.Certificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cert
+}
+
+// CertPool returns the certificates read from ca.crt, or nil when the
+// secret has none.
+func (s *KubernetesTLSSecret) CertPool() *x509.CertPool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pool
+}
+
+// GetCertificate can be used as tls.Config.GetCertificate so that reloads
+// are picked up by new connections.
+func (s *KubernetesTLSSecret) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cert := s.Certificate(); cert != nil {
+		return cert, nil
+	}
+	return nil, errors.New("kubernetes tls secret is not loaded")
+}
+
+// GetClientCertificate can be used as tls.Config.GetClientCertificate so
+// that reloads are picked up by new connections.
+func (s *KubernetesTLSSecret) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return s.GetCertificate(nil)
+}
+
+// Reload reads the secret again if the kubelet swapped its content since it
+// was last read, and reports whether it did. Directories which are not
+// managed by the kubelet are always read again.
+func (s *KubernetesTLSSecret) Reload() (bool, error) {
+	s.mu.RLock()
+	dir, version := s.dir, s.version
+	s.mu.RUnlock()
+	if dir == "" {
+		return false, errors.New("kubernetes tls secret is not loaded")
+	}
+
+	if current, _ := os.Readlink(filepath.Join(dir, kubernetesDataDir)); current != "" && current == version {
+		return false, nil
+	}
+	if err := s.load(dir); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Watch calls Reload every interval until ctx is done. Failures are
+// reported to onError, if not nil, and keep the previous content in use.
+func (s *KubernetesTLSSecret) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// load reads the secret mounted at dir. Files are read from the directory
+// ..data points to, so that a concurrent swap cannot mix the content of two
+// versions.
+func (s *KubernetesTLSSecret) load(dir string) error {
+	version, _ := os.Readlink(filepath.Join(dir, kubernetesDataDir))
+	base := dir
+	if version != "" {
+		base = filepath.Join(dir, version)
+		if filepath.IsAbs(version) {
+			base = version
+		}
+	}
+
+	certPEM, err := ioutil.ReadFile(filepath.Join(base, kubernetesTLSCert))
+	if err != nil {
+		return err
+	}
+	keyPEM, err := ioutil.ReadFile(filepath.Join(base, kubernetesTLSKey))
+	if err != nil {
+		return err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("%s: %v", dir, err)
+	}
+
+	var pool *x509.CertPool
+	caPEM, err := ioutil.ReadFile(filepath.Join(base, kubernetesCACert))
+	switch {
+	case err == nil:
+		pool = x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM(caPEM); !ok {
+			return fmt.Errorf("%s: failed to append certs from pem", filepath.Join(dir, kubernetesCACert))
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dir = dir
+	s.version = version
+	s.cert = &cert
+	s.pool = pool
+	return nil
+}
+
+// kubernetesTLSSecretDirValue adapts KubernetesTLSSecret for use as a flag.
+// Value of flag is the directory the secret is mounted at.
+type kubernetesTLSSecretDirValue struct {
+	dst *KubernetesTLSSecret
+}
+
+// String implements flag.Value.String.
+func (v kubernetesTLSSecretDirValue) String() string {
+	if v.dst == nil {
+		return ""
+	}
+	v.dst.mu.RLock()
+	defer v.dst.mu.RUnlock()
+	return v.dst.dir
+}
+
+// Set implements flag.Value.Set.
+func (v *kubernetesTLSSecretDirValue) Set(value string) error {
+	return v.dst.load(value)
+}
+
+// Type implements flag.Value.Type.
+func (*kubernetesTLSSecretDirValue) Type() string {
+	return TypeKubernetesTLSSecretDir
+}
+
+// Get implements flag.Getter. It returns a *KubernetesTLSSecret.
+func (v kubernetesTLSSecretDirValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *kubernetesTLSSecretDirValue) sourceRef() string {
+	return v.String()
+}
+
+// concurrentReload implements concurrentReloadable.
+func (*kubernetesTLSSecretDirValue) concurrentReload() {}
+
+// KubernetesTLSSecretDir creates and returns a new flag.Value compliant
+// parser of kubernetes.io/tls secrets mounted as a directory holding
+// tls.crt, tls.key and optionally ca.crt.
+func KubernetesTLSSecretDir(s *KubernetesTLSSecret) flag.Value {
+	return &kubernetesTLSSecretDirValue{dst: s}
+}
@@ -0,0 +1,111 @@
+package flagvars
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeKubernetesSecret writes a new version of a kubernetes.io/tls secret
+// into dir the way the kubelet does, swapping the ..data symlink.
+func writeKubernetesSecret(t *testing.T, dir, version string, cert *tls.Certificate) {
+	keyDer, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Leaf.Raw})
+
+	versionDir := filepath.Join(dir, version)
+	if err := os.Mkdir(versionDir, 0755); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	for name, data := range map[string][]byte{
+		kubernetesTLSCert: certPEM,
+		kubernetesTLSKey:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}),
+		kubernetesCACert:  certPEM,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(versionDir, name), data, 0600); err != nil {
+			t.Fatalf("expected success, got %q", err)
+		}
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			if err := os.Symlink(filepath.Join(kubernetesDataDir, name), link); err != nil {
+				t.Fatalf("expected success, got %q", err)
+			}
+		}
+	}
+
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(version, tmp); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, kubernetesDataDir)); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+}
+
+func TestKubernetesTLSSecretDir(t *testing.T) {
+	first, err := generateSelfSigned([]string{"first"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	second, err := generateSelfSigned([]string{"second"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	dir := t.TempDir()
+	writeKubernetesSecret(t, dir, "..2024_01_01_00_00_00.1", first)
+
+	var secret KubernetesTLSSecret
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(KubernetesTLSSecretDir(&secret), "tls-secret-dir", "TLS secret directory")
+	if err := fs.Parse([]string{"-tls-secret-dir", dir}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	cert, err := secret.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); !leaf.Equal(first.Leaf) {
+		t.Fatalf("got: %v, expected %v", leaf.Subject, first.Leaf.Subject)
+	}
+	if _, err := first.Leaf.Verify(x509.VerifyOptions{DNSName: "first", Roots: secret.CertPool()}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	if reloaded, err := secret.Reload(); err != nil || reloaded {
+		t.Fatalf("got: %v, %v, expected unchanged secret not to be reloaded", reloaded, err)
+	}
+
+	writeKubernetesSecret(t, dir, "..2024_01_02_00_00_00.2", second)
+	if reloaded, err := secret.Reload(); err != nil || !reloaded {
+		t.Fatalf("got: %v, %v, expected swapped secret to be reloaded", reloaded, err)
+	}
+	cert, _ = secret.GetCertificate(nil)
+	if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); !leaf.Equal(second.Leaf) {
+		t.Fatalf("got: %v, expected %v", leaf.Subject, second.Leaf.Subject)
+	}
+}
+
+func TestKubernetesTLSSecretDirFailures(t *testing.T) {
+	var secret KubernetesTLSSecret
+	if _, err := secret.GetCertificate(nil); err == nil {
+		t.Fatalf("expected failure before loading")
+	}
+	if err := KubernetesTLSSecretDir(&secret).Set(t.TempDir()); err == nil {
+		t.Fatalf("expected failure with empty directory")
+	}
+}
+
+func TestKubernetesTLSSecretDirVar(t *testing.T) {
+	var secret KubernetesTLSSecret
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Var(KubernetesTLSSecretDir(&secret), "tls-secret-dir", "TLS secret directory")
+}
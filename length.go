@@ -0,0 +1,244 @@
+package flagvars
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// bytesHolder is implemented by the values of this package holding bytes.
+type bytesHolder interface {
+	flag.Getter
+	Type() string
+	sourceRef() string
+	// bytes returns the bytes held by the value.
+	bytes() []byte
+	// save returns a function restoring the value to its current state.
+	save() func()
+}
+
+// bytes implements bytesHolder.
+func (bytesHex *bytesHexValue) bytes() []byte { return *bytesHex.dst }
+
+// save implements bytesHolder.
+func (bytesHex *bytesHexValue) save() func() {
+	saved, data := *bytesHex, *bytesHex.dst
+	return func() { *bytesHex, *bytesHex.dst = saved, data }
+}
+
+// bytes implements bytesHolder.
+func (bytesBase64 *bytesBase64Value) bytes() []byte { return *bytesBase64.dst }
+
+// save implements bytesHolder.
+func (bytesBase64 *bytesBase64Value) save() func() {
+	saved, data := *bytesBase64, *bytesBase64.dst
+	return func() { *bytesBase64, *bytesBase64.dst = saved, data }
+}
+
+// bytes implements bytesHolder.
+func (bf *bytesFileValue) bytes() []byte { return *bf.data }
+
+// save implements bytesHolder.
+func (bf *bytesFileValue) save() func() {
+	saved, data := *bf, *bf.data
+	return func() { *bf, *bf.data = saved, data }
+}
+
+// bytes implements bytesHolder.
+func (v *bytesEncodedValue) bytes() []byte { return *v.dst }
+
+// save implements bytesHolder.
+func (v *bytesEncodedValue) save() func() {
+	saved, data := *v, *v.dst
+	return func() { *v, *v.dst = saved, data }
+}
+
+// bytes implements bytesHolder.
+func (v *bytesValue) bytes() []byte { return *v.dst }
+
+// save implements bytesHolder.
+func (v *bytesValue) save() func() {
+	saved, data := *v, *v.dst
+	var restoreInner func()
+	if inner, ok := v.value.(bytesHolder); ok {
+		restoreInner = inner.save()
+	}
+	return func() {
+		*v, *v.dst = saved, data
+		if restoreInner != nil {
+			restoreInner()
+		}
+	}
+}
+
+// LengthConstraint checks the length of decoded bytes, returning an error
+// giving the decoded length against the expected one.
+type LengthConstraint func(n int) error
+
+// ExactLength requires bytes to be n long, 32 for AES-256 keys for example.
+func ExactLength(n int) LengthConstraint {
+	return func(got int) error {
+		if got != n {
+			return fmt.Errorf("decoded %d bytes, expected %d", got, n)
+		}
+		return nil
+	}
+}
+
+// LengthRange requires bytes to be between min and max long, inclusive. A
+// max of zero or less means no upper bound.
+func LengthRange(min, max int) LengthConstraint {
+	return func(got int) error {
+		switch {
+		case max > 0 && (got < min || got > max):
+			return fmt.Errorf("decoded %d bytes, expected between %d and %d", got, min, max)
+		case got < min:
+			return fmt.Errorf("decoded %d bytes, expected at least %d", got, min)
+		}
+		return nil
+	}
+}
+
+// LengthIn requires bytes to have one of the given lengths, such as 16, 24
+// or 32 for AES keys.
+func LengthIn(lengths ...int) LengthConstraint {
+	sorted := append([]int(nil), lengths...)
+	sort.Ints(sorted)
+	names := make([]string, len(sorted))
+	for i, n := range sorted {
+		names[i] = fmt.Sprint(n)
+	}
+	return func(got int) error {
+		for _, n := range sorted {
+			if got == n {
+				return nil
+			}
+		}
+		return fmt.Errorf("decoded %d bytes, expected one of %s", got, strings.Join(names, ", "))
+	}
+}
+
+// lengthValue constrains the length of the bytes held by a bytesHolder.
+type lengthValue struct {
+	bytesHolder
+	check LengthConstraint
+	// defaultErr is the failure of the default value to satisfy check,
+	// reported by Validate unless another value is set.
+	defaultErr error
+}
+
+// String implements flag.Value.String.
+func (v lengthValue) String() string {
+	if v.bytesHolder == nil {
+		return ""
+	}
+	return v.bytesHolder.String()
+}
+
+// defaultError implements defaultErrorer.
+func (v *lengthValue) defaultError() error {
+	if v.defaultErr != nil {
+		return v.defaultErr
+	}
+	if d, ok := v.bytesHolder.(defaultErrorer); ok {
+		return d.defaultError()
+	}
+	return nil
+}
+
+// Set implements flag.Value.Set. Values of the wrong length are rejected,
+// leaving the previous one in place.
+func (v *lengthValue) Set(value string) error {
+	restore := v.save()
+	if err := v.bytesHolder.Set(value); err != nil {
+		restore()
+		return err
+	}
+	if err := v.check(len(v.bytes())); err != nil {
+		restore()
+		return err
+	}
+	v.defaultErr = nil
+	return nil
+}
+
+// WithLength returns v, a bytes value of this package such as BytesHex or
+// Bytes, rejecting values whose decoded length does not satisfy check. A
+// non-empty default which does not is reported by Validate, and so by Parse,
+// unless the flag is set. It panics if v does not hold bytes.
+func WithLength(v flag.Value, check LengthConstraint) flag.Value {
+	h, ok := v.(bytesHolder)
+	if !ok {
+		panic(fmt.Sprintf("flagvars: WithLength called with a %T value, which does not hold bytes", v))
+	}
+	lv := &lengthValue{bytesHolder: h, check: check}
+	if n := len(h.bytes()); n > 0 {
+		lv.defaultErr = check(n)
+	}
+	return lv
+}
+
+// bytesArrayValue adapts a byte array for use as a flag. Value of flag is
+// parsed as Bytes does and must have the length of the array.
+type bytesArrayValue struct {
+	dst     reflect.Value
+	scratch []byte
+	value   flag.Value
+}
+
+// String implements flag.Value.String. An all zero array, which is what an
+// unset one holds, renders as an empty string.
+func (v bytesArrayValue) String() string {
+	if v.value == nil {
+		return ""
+	}
+	for _, b := range v.scratch {
+		if b != 0 {
+			return v.value.String()
+		}
+	}
+	return ""
+}
+
+// Set implements flag.Value.Set.
+func (v *bytesArrayValue) Set(value string) error {
+	if err := v.value.Set(value); err != nil {
+		return err
+	}
+	reflect.Copy(v.dst, reflect.ValueOf(v.scratch))
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (*bytesArrayValue) Type() string {
+	return TypeBytesArray
+}
+
+// Get implements flag.Getter. It returns the array p points to.
+func (v bytesArrayValue) Get() interface{} {
+	return v.dst.Interface()
+}
+
+// sourceRef implements reloadable.
+func (v *bytesArrayValue) sourceRef() string {
+	return v.value.(bytesHolder).sourceRef()
+}
+
+// BytesArray creates and returns a new flag.Value compliant parser storing
+// bytes in the array p points to, such as a *[32]byte, and rejecting values
+// of any other length. Values are parsed as Bytes does, without a prefix
+// being decoded with enc, Hex if nil. It panics if p does not point to a
+// byte array.
+func BytesArray[A any](p *A, enc Encoding) flag.Value {
+	dst := reflect.ValueOf(p).Elem()
+	if dst.Kind() != reflect.Array || dst.Type().Elem().Kind() != reflect.Uint8 {
+		panic(fmt.Sprintf("flagvars: BytesArray called with a %T, expected a pointer to a byte array", p))
+	}
+
+	v := &bytesArrayValue{dst: dst, scratch: make([]byte, dst.Len())}
+	reflect.Copy(reflect.ValueOf(v.scratch), dst)
+	v.value = WithLength(Bytes(&v.scratch, v.scratch, enc), ExactLength(dst.Len()))
+	return v
+}
@@ -0,0 +1,152 @@
+package flagvars
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestWithLength(t *testing.T) {
+	testCases := []struct {
+		check    LengthConstraint
+		input    string
+		expected string
+	}{
+		// Positive cases
+		{ExactLength(2), "0102", ""},
+		{LengthRange(1, 3), "010203", ""},
+		{LengthRange(2, 0), "0102030405", ""},
+		{LengthIn(32, 16, 24), strings.Repeat("00", 24), ""},
+
+		// Negative cases
+		{ExactLength(32), strings.Repeat("00", 31), "decoded 31 bytes, expected 32"},
+		{LengthRange(2, 3), "01", "decoded 1 bytes, expected between 2 and 3"},
+		{LengthRange(2, 0), "01", "decoded 1 bytes, expected at least 2"},
+		{LengthIn(32, 16, 24), strings.Repeat("00", 20), "decoded 20 bytes, expected one of 16, 24, 32"},
+	}
+
+	for _, tc := range testCases {
+		data := []byte{9}
+		v := WithLength(BytesHex(&data, data), tc.check)
+		err := v.Set(tc.input)
+		if tc.expected == "" {
+			if err != nil {
+				t.Errorf("expected success, got %q", err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("got: %v, expected %q", err, tc.expected)
+			continue
+		}
+		if !bytes.Equal(data, []byte{9}) || v.String() != "09" {
+			t.Errorf("got: %X, %q, expected previous value to be kept", data, v.String())
+		}
+	}
+}
+
+func TestWithLengthSource(t *testing.T) {
+	t.Setenv("TEST_LENGTH_KEY", "010203")
+
+	var data []byte
+	v := WithLength(Bytes(&data, nil, nil), ExactLength(2))
+	if err := v.Set("hex:0102"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := v.Set("hex:env:TEST_LENGTH_KEY"); err == nil {
+		t.Fatalf("expected failure with wrong length")
+	}
+	if v.String() != "hex:0102" || v.(reloadable).sourceRef() != "" {
+		t.Fatalf("got: %q, expected previous value and source to be kept", v.String())
+	}
+	if v.(interface{ Type() string }).Type() != TypeBytes {
+		t.Fatalf("expected type of the wrapped value")
+	}
+}
+
+func TestWithLengthDefault(t *testing.T) {
+	var short, exact, empty []byte
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(WithLength(BytesHex(&short, []byte{1}), ExactLength(2)), "short", "short key")
+	fs.Var(WithLength(BytesHex(&exact, []byte{1, 2}), ExactLength(2)), "exact", "exact key")
+	fs.Var(WithLength(BytesHex(&empty, nil), ExactLength(2)), "empty", "empty key")
+	err := Parse(fs, nil)
+	if err == nil || err.Error() != "invalid default value for flag -short: decoded 1 bytes, expected 2" {
+		t.Fatalf("got: %v, expected short default to be reported", err)
+	}
+	if err := Parse(fs, []string{"-short", "0102"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "*flag.stringValue") {
+			t.Fatalf("got: %q, expected panic naming the value type", msg)
+		}
+	}()
+	tmp := flag.NewFlagSet("", flag.ContinueOnError)
+	tmp.String("s", "", "")
+	WithLength(tmp.Lookup("s").Value, ExactLength(2))
+}
+
+func TestWithLengthPrintDefaults(t *testing.T) {
+	var (
+		data []byte
+		key  [32]byte
+	)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(WithLength(BytesHex(&data, nil), ExactLength(32)), "key", "key")
+	fs.Var(BytesArray(&key, nil), "array", "array key")
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	if strings.Contains(usage.String(), "panic") || strings.Contains(usage.String(), "default") {
+		t.Fatalf("got: %q, expected no default", usage.String())
+	}
+}
+
+func TestBytesArray(t *testing.T) {
+	var key [4]byte
+	v := BytesArray(&key, nil)
+	if v.String() != "" {
+		t.Fatalf("got: %q, expected zero array to render empty", v.String())
+	}
+	if err := v.Set("base64:AQIDBA=="); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if key != [4]byte{1, 2, 3, 4} {
+		t.Fatalf("got: %X, expected 01020304", key)
+	}
+	if err := v.Set("010203"); err == nil || err.Error() != "decoded 3 bytes, expected 4" {
+		t.Fatalf("got: %v, expected length failure", err)
+	}
+	if key != [4]byte{1, 2, 3, 4} || v.String() != "base64:AQIDBA==" {
+		t.Fatalf("got: %X, %q, expected previous value to be kept", key, v.String())
+	}
+	if got, ok := v.(flag.Getter).Get().([4]byte); !ok || got != key {
+		t.Fatalf("got: %v, expected %X", v.(flag.Getter).Get(), key)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic with a non array target")
+		}
+	}()
+	BytesArray(new([]byte), nil)
+}
+
+func TestBytesArrayVar(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	key := BytesArrayFlag[[32]byte](fs, nil, "aes-key", "AES-256 key")
+	if err := fs.Parse([]string{"-aes-key", strings.Repeat("ab", 32)}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if key[31] != 0xab {
+		t.Fatalf("got: %X, expected AB...", *key)
+	}
+	if got, err := Lookup[[32]byte](fs, "aes-key"); err != nil || got != *key {
+		t.Fatalf("got: %X, %v, expected %X", got, err, *key)
+	}
+}
@@ -0,0 +1,28 @@
+package flagvars
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// Lookup returns the value of the name flag of fs, or flag.CommandLine when
+// fs is nil, as the type T its flag.Getter returns: *x509.Certificate for a
+// Certificate flag, []byte for a BytesHex flag, int for a flag.Int flag and
+// so on.
+func Lookup[T any](fs *flag.FlagSet, name string) (T, error) {
+	var zero T
+	f := commandLine(fs).Lookup(name)
+	if f == nil {
+		return zero, fmt.Errorf("flag -%s is not defined", name)
+	}
+	g, ok := f.Value.(flag.Getter)
+	if !ok {
+		return zero, fmt.Errorf("flag -%s does not implement flag.Getter", name)
+	}
+	v, ok := g.Get().(T)
+	if !ok {
+		return zero, fmt.Errorf("flag -%s holds a %T, not a %v", name, g.Get(), reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return v, nil
+}
@@ -0,0 +1,70 @@
+package flagvars
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestGetter(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	keyDer, _ := x509.MarshalPKCS8PrivateKey(generated.PrivateKey)
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	CertificateFlag(fs, "tls-cert", "", "certificate")
+	CertPoolFlag(fs, "tls-ca", "", "CA certificates")
+	PrivateKeyFlag(fs, "tls-key", "", "private key")
+	BytesHexFlag(fs, "hmac-key", nil, "HMAC key")
+	TLSVersionFlag(fs, "tls-min-version", 0, "minimum TLS version")
+	CipherSuitesFlag(fs, "tls-cipher-suites", nil, "cipher suites")
+	fs.Var(RSAPublicKey(new(rsa.PublicKey)), "rsa-pub", "RSA public key")
+	fs.Int("workers", 4, "workers")
+	if err := fs.Parse([]string{"-tls-cert", certPEM, "-tls-key", keyPEM, "-hmac-key", "0102"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := f.Value.(flag.Getter); !ok {
+			t.Fatalf("expected flag -%s to implement flag.Getter", f.Name)
+		}
+	})
+
+	cert, err := Lookup[*x509.Certificate](fs, "tls-cert")
+	if err != nil || !cert.Equal(generated.Leaf) {
+		t.Fatalf("got: %v, %v, expected the parsed certificate", cert, err)
+	}
+	if _, err := Lookup[*x509.CertPool](fs, "tls-ca"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	key, err := Lookup[crypto.Signer](fs, "tls-key")
+	if err != nil || !publicKeysEqual(key.Public(), generated.Leaf.PublicKey) {
+		t.Fatalf("got: %v, expected the parsed private key", err)
+	}
+	hmacKey, err := Lookup[[]byte](fs, "hmac-key")
+	if err != nil || !bytes.Equal(hmacKey, []byte{1, 2}) {
+		t.Fatalf("got: %X, %v, expected 0102", hmacKey, err)
+	}
+	if workers, err := Lookup[int](fs, "workers"); err != nil || workers != 4 {
+		t.Fatalf("got: %v, %v, expected 4", workers, err)
+	}
+
+	if _, err := Lookup[[]byte](fs, "tls-cert"); err == nil || !strings.Contains(err.Error(), "not a []uint8") {
+		t.Fatalf("got: %v, expected type mismatch failure", err)
+	}
+	if _, err := Lookup[crypto.Signer](fs, "hmac-key"); err == nil || !strings.Contains(err.Error(), "not a crypto.Signer") {
+		t.Fatalf("got: %v, expected type mismatch failure", err)
+	}
+	if _, err := Lookup[int](fs, "undefined"); err == nil {
+		t.Fatalf("expected failure with undefined flag")
+	}
+}
@@ -0,0 +1,141 @@
+package pflagvars
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/glerchundi/flagvars"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// pemExtensions lists the file extensions CompletePEMFile offers.
+var pemExtensions = []string{"pem", "crt", "cer", "cert", "key", "pub"}
+
+// CompletePEMFile completes the names of PEM encoded files, as taken by
+// values such as flagvars.BytesFile which read bare paths.
+func CompletePEMFile(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return pemExtensions, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// CompletePEMSource completes the names of PEM encoded files prefixed by @,
+// the form certificate and key values read files from, since they take bare
+// values as inline PEM. Directories are offered too, so that completion can
+// descend into them.
+func CompletePEMSource(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dir, base := filepath.Split(strings.TrimPrefix(toComplete, "@"))
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+	entries, err := ioutil.ReadDir(readDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		switch {
+		case e.IsDir():
+			names = append(names, "@"+dir+name+string(filepath.Separator))
+		case hasPEMExtension(name):
+			names = append(names, "@"+dir+name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// hasPEMExtension reports whether name has one of pemExtensions.
+func hasPEMExtension(name string) bool {
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	for _, e := range pemExtensions {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompleteDir completes the names of directories.
+func CompleteDir(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+// CompleteTLSVersion completes the names of TLS versions.
+func CompleteTLSVersion(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, name := range flagvars.TLSVersionNames() {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteCipherSuites completes the last element of a comma separated list
+// of cipher suites, leaving out those already listed. Insecure cipher suites
+// are not offered.
+func CompleteCipherSuites(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix, last := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix, last = toComplete[:i+1], toComplete[i+1:]
+	}
+	listed := make(map[string]bool)
+	for _, name := range strings.Split(prefix, ",") {
+		listed[strings.TrimSpace(name)] = true
+	}
+
+	var names []string
+	for _, name := range flagvars.CipherSuiteNames() {
+		if !listed[name] && strings.HasPrefix(name, last) {
+			names = append(names, prefix+name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completions maps flagvars Type names to their completion function.
+var completions = map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+	flagvars.TypeCertificate:            CompletePEMSource,
+	flagvars.TypeCertificates:           CompletePEMSource,
+	flagvars.TypeCertPool:               CompletePEMSource,
+	flagvars.TypeTLSCertificate:         CompletePEMSource,
+	flagvars.TypeCertificateAuthority:   CompletePEMSource,
+	flagvars.TypeTrustBundle:            CompletePEMSource,
+	flagvars.TypeTLSKeyPair:             CompletePEMSource,
+	flagvars.TypePrivateKey:             CompletePEMSource,
+	flagvars.TypeRSAPrivateKey:          CompletePEMSource,
+	flagvars.TypeRSAPublicKey:           CompletePEMSource,
+	flagvars.TypeECDSAPrivateKey:        CompletePEMSource,
+	flagvars.TypeECDSAPublicKey:         CompletePEMSource,
+	flagvars.TypeBytesFile:              CompletePEMFile,
+	flagvars.TypeBytesFileLazy:          CompletePEMFile,
+	flagvars.TypeSecretBytesFile:        CompletePEMFile,
+	flagvars.TypeKubernetesTLSSecretDir: CompleteDir,
+	flagvars.TypeTLSVersion:             CompleteTLSVersion,
+	flagvars.TypeCipherSuites:           CompleteCipherSuites,
+}
+
+// RegisterCompletions registers the completion function matching the type of
+// every flagvars flag of cmd, local and persistent, which has none yet.
+func RegisterCompletions(cmd *cobra.Command) error {
+	var err error
+	register := func(f *pflag.Flag) {
+		complete, ok := completions[f.Value.Type()]
+		if !ok || err != nil {
+			return
+		}
+		if _, exists := cmd.GetFlagCompletionFunc(f.Name); exists {
+			return
+		}
+		err = cmd.RegisterFlagCompletionFunc(f.Name, complete)
+	}
+	cmd.Flags().VisitAll(register)
+	cmd.PersistentFlags().VisitAll(register)
+	return err
+}
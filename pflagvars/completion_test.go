@@ -0,0 +1,111 @@
+package pflagvars
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/glerchundi/flagvars"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestCompleteTLSVersion(t *testing.T) {
+	names, directive := CompleteTLSVersion(nil, nil, "1.")
+	if len(names) != 4 || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Fatalf("got: %v, %v, expected 4 versions without file completion", names, directive)
+	}
+	if names, _ := CompleteTLSVersion(nil, nil, "1.3"); !reflect.DeepEqual(names, []string{"1.3"}) {
+		t.Fatalf("got: %v, expected [1.3]", names)
+	}
+}
+
+func TestCompleteCipherSuites(t *testing.T) {
+	names, directive := CompleteCipherSuites(nil, nil, "TLS_AES_128_GCM_SHA256,TLS_AES_")
+	expected := []string{"TLS_AES_128_GCM_SHA256,TLS_AES_256_GCM_SHA384"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("got: %v, expected %v", names, expected)
+	}
+	if directive&cobra.ShellCompDirectiveNoSpace == 0 {
+		t.Fatalf("expected no space to be appended after a cipher suite")
+	}
+}
+
+func TestCompletePEMSource(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"tls.crt", "tls.key", "notes.txt", ".hidden.pem"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatalf("expected success, got %q", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "certs"), 0700); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	prefix := dir + string(filepath.Separator)
+	names, directive := CompletePEMSource(nil, nil, "@"+prefix)
+	expected := []string{"@" + prefix + "certs" + string(filepath.Separator), "@" + prefix + "tls.crt", "@" + prefix + "tls.key"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("got: %v, expected %v", names, expected)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace {
+		t.Fatalf("got: %v, expected no file completion and no space", directive)
+	}
+	if names, _ := CompletePEMSource(nil, nil, prefix+"tls.c"); !reflect.DeepEqual(names, []string{"@" + prefix + "tls.crt"}) {
+		t.Fatalf("got: %v, expected @ to be added", names)
+	}
+}
+
+func TestRegisterCompletions(t *testing.T) {
+	var (
+		cert    x509.Certificate
+		version uint16
+	)
+	cmd := &cobra.Command{Use: "test"}
+	CertificateVar(cmd.Flags(), &cert, "tls-cert", "certificate")
+	TLSVersionVar(cmd.PersistentFlags(), &version, "tls-min-version", "minimum TLS version")
+	cmd.Flags().String("name", "", "name")
+
+	if err := RegisterCompletions(cmd); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	complete, ok := cmd.GetFlagCompletionFunc("tls-cert")
+	if !ok {
+		t.Fatalf("expected completion for -tls-cert")
+	}
+	if _, directive := complete(cmd, nil, "@"); directive&cobra.ShellCompDirectiveNoFileComp == 0 {
+		t.Fatalf("got: %v, expected @ prefixed file names instead of shell file completion", directive)
+	}
+	var (
+		data   []byte
+		secret flagvars.Secret
+	)
+	BytesFileVar(cmd.Flags(), &data, "data-file", "", "data file")
+	cmd.Flags().Var(flagvars.SecretBytesFile(&secret).(pflag.Value), "secret-file", "secret file")
+	if err := RegisterCompletions(cmd); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	for _, name := range []string{"data-file", "secret-file"} {
+		complete, ok := cmd.GetFlagCompletionFunc(name)
+		if !ok {
+			t.Fatalf("expected completion for -%s", name)
+		}
+		if exts, directive := complete(cmd, nil, ""); directive != cobra.ShellCompDirectiveFilterFileExt || len(exts) == 0 {
+			t.Fatalf("got: %v, %v, expected PEM file extensions for -%s", exts, directive, name)
+		}
+	}
+	if _, ok := cmd.GetFlagCompletionFunc("tls-min-version"); !ok {
+		t.Fatalf("expected completion for -tls-min-version")
+	}
+	if _, ok := cmd.GetFlagCompletionFunc("name"); ok {
+		t.Fatalf("expected no completion for -name")
+	}
+
+	// Registering twice leaves existing completions alone.
+	if err := RegisterCompletions(cmd); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+}
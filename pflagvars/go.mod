@@ -0,0 +1,13 @@
+module github.com/glerchundi/flagvars/pflagvars
+
+go 1.18
+
+require (
+	github.com/glerchundi/flagvars v0.0.0-00010101000000-000000000000
+	github.com/spf13/cobra v1.8.1
+	github.com/spf13/pflag v1.0.5
+)
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
+
+replace github.com/glerchundi/flagvars => ../
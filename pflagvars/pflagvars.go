@@ -0,0 +1,176 @@
+// Package pflagvars registers flagvars values with spf13/pflag flag sets and
+// provides spf13/cobra shell completions for them.
+//
+// Every XxxVar function has an XxxVarP variant accepting a shorthand letter,
+// as pflag does. The type shown in usage messages is the flagvars Type name,
+// flagvars.TypeCertificate for example.
+package pflagvars
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+
+	"github.com/glerchundi/flagvars"
+	"github.com/spf13/pflag"
+)
+
+// varP defines a flag in fs from v, which has a Type method as every
+// flagvars value does.
+func varP(fs *pflag.FlagSet, v flag.Value, name, shorthand, usage string) {
+	fs.VarP(v.(pflag.Value), name, shorthand, usage)
+}
+
+// CertificateVar defines an x509.Certificate flag, see flagvars.Certificate.
+func CertificateVar(fs *pflag.FlagSet, p *x509.Certificate, name, usage string) {
+	CertificateVarP(fs, p, name, "", usage)
+}
+
+// CertificateVarP is like CertificateVar, but accepts a shorthand letter.
+func CertificateVarP(fs *pflag.FlagSet, p *x509.Certificate, name, shorthand, usage string) {
+	varP(fs, flagvars.Certificate(p), name, shorthand, usage)
+}
+
+// CertificatesVar defines an []*x509.Certificate flag, see
+// flagvars.Certificates.
+func CertificatesVar(fs *pflag.FlagSet, p *[]*x509.Certificate, name, usage string) {
+	CertificatesVarP(fs, p, name, "", usage)
+}
+
+// CertificatesVarP is like CertificatesVar, but accepts a shorthand letter.
+func CertificatesVarP(fs *pflag.FlagSet, p *[]*x509.Certificate, name, shorthand, usage string) {
+	varP(fs, flagvars.Certificates(p), name, shorthand, usage)
+}
+
+// CertPoolVar defines an x509.CertPool flag, see flagvars.CertPool.
+func CertPoolVar(fs *pflag.FlagSet, p *x509.CertPool, name, usage string) {
+	CertPoolVarP(fs, p, name, "", usage)
+}
+
+// CertPoolVarP is like CertPoolVar, but accepts a shorthand letter.
+func CertPoolVarP(fs *pflag.FlagSet, p *x509.CertPool, name, shorthand, usage string) {
+	varP(fs, flagvars.CertPool(p), name, shorthand, usage)
+}
+
+// TLSCertificateVar defines a tls.Certificate flag, see
+// flagvars.TLSCertificate.
+func TLSCertificateVar(fs *pflag.FlagSet, p *tls.Certificate, name, usage string) {
+	TLSCertificateVarP(fs, p, name, "", usage)
+}
+
+// TLSCertificateVarP is like TLSCertificateVar, but accepts a shorthand
+// letter.
+func TLSCertificateVarP(fs *pflag.FlagSet, p *tls.Certificate, name, shorthand, usage string) {
+	varP(fs, flagvars.TLSCertificate(p), name, shorthand, usage)
+}
+
+// PrivateKeyVar defines a crypto.Signer flag, see flagvars.PrivateKey.
+func PrivateKeyVar(fs *pflag.FlagSet, p *crypto.Signer, name, usage string) {
+	PrivateKeyVarP(fs, p, name, "", usage)
+}
+
+// PrivateKeyVarP is like PrivateKeyVar, but accepts a shorthand letter.
+func PrivateKeyVarP(fs *pflag.FlagSet, p *crypto.Signer, name, shorthand, usage string) {
+	varP(fs, flagvars.PrivateKey(p), name, shorthand, usage)
+}
+
+// RSAPrivateKeyVar defines an rsa.PrivateKey flag, see
+// flagvars.RSAPrivateKey.
+func RSAPrivateKeyVar(fs *pflag.FlagSet, p *rsa.PrivateKey, name, usage string) {
+	RSAPrivateKeyVarP(fs, p, name, "", usage)
+}
+
+// RSAPrivateKeyVarP is like RSAPrivateKeyVar, but accepts a shorthand
+// letter.
+func RSAPrivateKeyVarP(fs *pflag.FlagSet, p *rsa.PrivateKey, name, shorthand, usage string) {
+	varP(fs, flagvars.RSAPrivateKey(p), name, shorthand, usage)
+}
+
+// RSAPublicKeyVar defines an rsa.PublicKey flag, see flagvars.RSAPublicKey.
+func RSAPublicKeyVar(fs *pflag.FlagSet, p *rsa.PublicKey, name, usage string) {
+	RSAPublicKeyVarP(fs, p, name, "", usage)
+}
+
+// RSAPublicKeyVarP is like RSAPublicKeyVar, but accepts a shorthand letter.
+func RSAPublicKeyVarP(fs *pflag.FlagSet, p *rsa.PublicKey, name, shorthand, usage string) {
+	varP(fs, flagvars.RSAPublicKey(p), name, shorthand, usage)
+}
+
+// ECDSAPrivateKeyVar defines an ecdsa.PrivateKey flag, see
+// flagvars.ECDSAPrivateKey.
+func ECDSAPrivateKeyVar(fs *pflag.FlagSet, p *ecdsa.PrivateKey, name, usage string) {
+	ECDSAPrivateKeyVarP(fs, p, name, "", usage)
+}
+
+// ECDSAPrivateKeyVarP is like ECDSAPrivateKeyVar, but accepts a shorthand
+// letter.
+func ECDSAPrivateKeyVarP(fs *pflag.FlagSet, p *ecdsa.PrivateKey, name, shorthand, usage string) {
+	varP(fs, flagvars.ECDSAPrivateKey(p), name, shorthand, usage)
+}
+
+// ECDSAPublicKeyVar defines an ecdsa.PublicKey flag, see
+// flagvars.ECDSAPublicKey.
+func ECDSAPublicKeyVar(fs *pflag.FlagSet, p *ecdsa.PublicKey, name, usage string) {
+	ECDSAPublicKeyVarP(fs, p, name, "", usage)
+}
+
+// ECDSAPublicKeyVarP is like ECDSAPublicKeyVar, but accepts a shorthand
+// letter.
+func ECDSAPublicKeyVarP(fs *pflag.FlagSet, p *ecdsa.PublicKey, name, shorthand, usage string) {
+	varP(fs, flagvars.ECDSAPublicKey(p), name, shorthand, usage)
+}
+
+// BytesHexVar defines a HEX encoded []byte flag, see flagvars.BytesHex.
+func BytesHexVar(fs *pflag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	BytesHexVarP(fs, p, name, "", value, usage)
+}
+
+// BytesHexVarP is like BytesHexVar, but accepts a shorthand letter.
+func BytesHexVarP(fs *pflag.FlagSet, p *[]byte, name, shorthand string, value []byte, usage string) {
+	varP(fs, flagvars.BytesHex(p, value), name, shorthand, usage)
+}
+
+// BytesBase64Var defines a Base64 encoded []byte flag, see
+// flagvars.BytesBase64.
+func BytesBase64Var(fs *pflag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	BytesBase64VarP(fs, p, name, "", value, usage)
+}
+
+// BytesBase64VarP is like BytesBase64Var, but accepts a shorthand letter.
+func BytesBase64VarP(fs *pflag.FlagSet, p *[]byte, name, shorthand string, value []byte, usage string) {
+	varP(fs, flagvars.BytesBase64(p, value), name, shorthand, usage)
+}
+
+// BytesFileVar defines a []byte flag read from a file, see
+// flagvars.BytesFile.
+func BytesFileVar(fs *pflag.FlagSet, p *[]byte, name string, value string, usage string) {
+	BytesFileVarP(fs, p, name, "", value, usage)
+}
+
+// BytesFileVarP is like BytesFileVar, but accepts a shorthand letter.
+func BytesFileVarP(fs *pflag.FlagSet, p *[]byte, name, shorthand string, value string, usage string) {
+	varP(fs, flagvars.BytesFile(p, value), name, shorthand, usage)
+}
+
+// TLSVersionVar defines a TLS version flag, see flagvars.TLSVersion.
+func TLSVersionVar(fs *pflag.FlagSet, p *uint16, name, usage string) {
+	TLSVersionVarP(fs, p, name, "", usage)
+}
+
+// TLSVersionVarP is like TLSVersionVar, but accepts a shorthand letter.
+func TLSVersionVarP(fs *pflag.FlagSet, p *uint16, name, shorthand, usage string) {
+	varP(fs, flagvars.TLSVersion(p), name, shorthand, usage)
+}
+
+// CipherSuitesVar defines a cipher suites flag, see flagvars.CipherSuites.
+func CipherSuitesVar(fs *pflag.FlagSet, p *[]uint16, name, usage string) {
+	CipherSuitesVarP(fs, p, name, "", usage)
+}
+
+// CipherSuitesVarP is like CipherSuitesVar, but accepts a shorthand letter.
+func CipherSuitesVarP(fs *pflag.FlagSet, p *[]uint16, name, shorthand, usage string) {
+	varP(fs, flagvars.CipherSuites(p), name, shorthand, usage)
+}
@@ -0,0 +1,112 @@
+package pflagvars
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glerchundi/flagvars"
+	"github.com/spf13/pflag"
+)
+
+func testCertificatePEM(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestVarP(t *testing.T) {
+	certPEM := testCertificatePEM(t)
+
+	var (
+		cert    x509.Certificate
+		pool    = x509.NewCertPool()
+		key     []byte
+		seed    []byte
+		version uint16
+		suites  []uint16
+	)
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	CertificateVarP(fs, &cert, "tls-cert", "c", "certificate")
+	CertPoolVar(fs, pool, "tls-ca", "CA certificates")
+	BytesHexVarP(fs, &key, "hmac-key", "k", nil, "HMAC key")
+	BytesBase64Var(fs, &seed, "seed", []byte{9}, "seed")
+	TLSVersionVar(fs, &version, "tls-min-version", "minimum TLS version")
+	CipherSuitesVar(fs, &suites, "tls-cipher-suites", "cipher suites")
+
+	err := fs.Parse([]string{
+		"-c", certPEM,
+		"--tls-ca", certPEM,
+		"-k", "0102",
+		"--tls-min-version", "1.3",
+		"--tls-cipher-suites", "TLS_AES_128_GCM_SHA256",
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	block, _ := pem.Decode([]byte(certPEM))
+	if !bytes.Equal(cert.Raw, block.Bytes) {
+		t.Fatalf("expected certificate to be set")
+	}
+	if !bytes.Equal(key, []byte{1, 2}) || !bytes.Equal(seed, []byte{9}) {
+		t.Fatalf("got: %X, %X, expected 0102, 09", key, seed)
+	}
+	if version != tls.VersionTLS13 || len(suites) != 1 || suites[0] != tls.TLS_AES_128_GCM_SHA256 {
+		t.Fatalf("got: %x, %v, expected TLS 1.3 and a cipher suite", version, suites)
+	}
+
+	for name, expected := range map[string]string{
+		"tls-cert":        flagvars.TypeCertificate,
+		"tls-ca":          flagvars.TypeCertPool,
+		"hmac-key":        flagvars.TypeBytesHex,
+		"tls-min-version": flagvars.TypeTLSVersion,
+	} {
+		if got := fs.Lookup(name).Value.Type(); got != expected {
+			t.Fatalf("got: %q, expected %q", got, expected)
+		}
+	}
+	if usage := fs.FlagUsages(); !strings.Contains(usage, "--tls-cert certificate") {
+		t.Fatalf("expected usage to show the type of the flag, got %q", usage)
+	}
+}
+
+func TestVar(t *testing.T) {
+	var (
+		cert     x509.Certificate
+		certs    []*x509.Certificate
+		pool     = x509.NewCertPool()
+		tlsCert  tls.Certificate
+		ecdsaKey ecdsa.PrivateKey
+		ecdsaPub ecdsa.PublicKey
+		data     []byte
+	)
+	fs := pflag.NewFlagSet("test", pflag.ExitOnError)
+	CertificateVar(fs, &cert, "cert", "certificate")
+	CertificatesVar(fs, &certs, "certs", "certificates")
+	CertPoolVar(fs, pool, "pool", "pool")
+	TLSCertificateVar(fs, &tlsCert, "tls", "TLS certificate")
+	ECDSAPrivateKeyVar(fs, &ecdsaKey, "ecdsa-key", "ECDSA private key")
+	ECDSAPublicKeyVar(fs, &ecdsaPub, "ecdsa-pub", "ECDSA public key")
+	BytesFileVar(fs, &data, "data", "", "data file")
+}
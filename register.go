@@ -0,0 +1,280 @@
+package flagvars
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	certificateType  = reflect.TypeOf(x509.Certificate{})
+	certificatesType = reflect.TypeOf([]*x509.Certificate(nil))
+	certPoolType     = reflect.TypeOf(x509.CertPool{})
+	tlsCertType      = reflect.TypeOf(tls.Certificate{})
+	rsaPrivateType   = reflect.TypeOf(rsa.PrivateKey{})
+	rsaPublicType    = reflect.TypeOf(rsa.PublicKey{})
+	ecdsaPrivateType = reflect.TypeOf(ecdsa.PrivateKey{})
+	ecdsaPublicType  = reflect.TypeOf(ecdsa.PublicKey{})
+	signerType       = reflect.TypeOf((*crypto.Signer)(nil)).Elem()
+	secretType       = reflect.TypeOf(Secret{})
+	bytesType        = reflect.TypeOf([]byte(nil))
+	flagValueType    = reflect.TypeOf((*flag.Value)(nil)).Elem()
+)
+
+// Register defines a flag in fs for every field of the struct pointed to by
+// v which has a flag tag, picking the flagvars value matching the type of the
+// field:
+//
+//	type Config struct {
+//		Cert  x509.Certificate `flag:"tls-cert" usage:"server certificate"`
+//		Roots *x509.CertPool   `flag:"tls-ca" usage:"client CAs"`
+//		Key   []byte           `flag:"hmac-key" format:"base64" env:"HMAC_KEY"`
+//		DB    struct {
+//			Password string `flag:"password"`
+//		} `flag:"db"`
+//	}
+//
+// The following tags are understood:
+//
+//   - flag: the name of the flag, "-" to skip the field. On a nested struct,
+//     the prefix of the flags of its fields, joined with a dash, db-password
+//     above. Nested structs without a flag tag add no prefix.
+//   - usage: the usage message of the flag.
+//   - format: how the value is encoded: for certificates and keys pem, the
+//     default, or file, taking values without a source prefix as the path of
+//     a PEM file, or for []byte hex, the default, fingerprint
+//     (colon separated hex), base64, base64url, base64rawurl, base64raw,
+//     base32, base32raw, base32hex, base32hexraw, base58, ascii85 or file,
+//     or for Secret hex, the default, base64 or file.
+//   - env: an environment variable setting the flag, as BindEnv does.
+//
+// Besides the flagvars types (x509.Certificate, []*x509.Certificate,
+// x509.CertPool, tls.Certificate, rsa and ecdsa keys, crypto.Signer, Secret
+// and []byte), fields may be strings, booleans, integers, floats, durations or
+// implement flag.Value through their address. The current values of the
+// fields are the defaults of the flags, a nil *x509.CertPool being allocated.
+func Register(fs *flag.FlagSet, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("expected a non-nil pointer to a struct")
+	}
+	return register(fs, rv.Elem(), "")
+}
+
+// register defines the flags of the fields of the struct value sv, prefixing
+// their names with prefix.
+func register(fs *flag.FlagSet, sv reflect.Value, prefix string) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		name, tagged := field.Tag.Lookup("flag")
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if prefix != "" && name != "" {
+			name = prefix + "-" + name
+		}
+
+		fv := sv.Field(i)
+		if isNested(field.Type) {
+			if name == "" {
+				name = prefix
+			}
+			if err := register(fs, fv, name); err != nil {
+				return err
+			}
+			continue
+		}
+		if !tagged || name == "" {
+			continue
+		}
+
+		format := field.Tag.Get("format")
+		val := value(fv, format)
+		if val == nil {
+			if _, ok := formats[field.Type]; ok || field.Type == bytesType || field.Type == secretType {
+				return fmt.Errorf("field %s: unknown format %q for %s", field.Name, format, field.Type)
+			}
+			return fmt.Errorf("field %s: unsupported type %s", field.Name, field.Type)
+		}
+		fs.Var(val, name, field.Tag.Get("usage"))
+
+		if variable := field.Tag.Get("env"); variable != "" {
+			if err := bindEnv(fs, fs.Lookup(name), variable); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formats maps the types with a PEM representation to their constructor.
+var formats = map[reflect.Type]func(p interface{}) flag.Value{
+	certificateType:  func(p interface{}) flag.Value { return Certificate(p.(*x509.Certificate)) },
+	certificatesType: func(p interface{}) flag.Value { return Certificates(p.(*[]*x509.Certificate)) },
+	certPoolType:     func(p interface{}) flag.Value { return CertPool(p.(*x509.CertPool)) },
+	tlsCertType:      func(p interface{}) flag.Value { return TLSCertificate(p.(*tls.Certificate)) },
+	rsaPrivateType:   func(p interface{}) flag.Value { return RSAPrivateKey(p.(*rsa.PrivateKey)) },
+	rsaPublicType:    func(p interface{}) flag.Value { return RSAPublicKey(p.(*rsa.PublicKey)) },
+	ecdsaPrivateType: func(p interface{}) flag.Value { return ECDSAPrivateKey(p.(*ecdsa.PrivateKey)) },
+	ecdsaPublicType:  func(p interface{}) flag.Value { return ECDSAPublicKey(p.(*ecdsa.PublicKey)) },
+	signerType:       func(p interface{}) flag.Value { return PrivateKey(p.(*crypto.Signer)) },
+}
+
+// byteFormats maps the format tags of []byte fields to their constructor.
+var byteFormats = map[string]func(p *[]byte, value []byte) flag.Value{
+	"":    BytesHex,
+	"hex": BytesHex,
+	"fingerprint": func(p *[]byte, value []byte) flag.Value {
+		return BytesHexFormatted(p, value, HexUpperColon)
+	},
+	"base64":       BytesBase64,
+	"base64url":    BytesBase64URL,
+	"base64rawurl": BytesBase64RawURL,
+	"base64raw":    BytesBase64Raw,
+	"base32":       BytesBase32,
+	"base32raw":    BytesBase32Raw,
+	"base32hex":    BytesBase32Hex,
+	"base32hexraw": BytesBase32HexRaw,
+	"base58":       BytesBase58,
+	"ascii85":      BytesAscii85,
+}
+
+// isNested reports whether fields of type t are walked rather than set by a
+// single flag.
+func isNested(t reflect.Type) bool {
+	_, ok := formats[t]
+	return t.Kind() == reflect.Struct && !ok && t != secretType && !reflect.PtrTo(t).Implements(flagValueType)
+}
+
+// value returns the flag.Value setting the field fv, or nil if its type or
+// format is not supported.
+func value(fv reflect.Value, format string) flag.Value {
+	t := fv.Type()
+	if t.Kind() == reflect.Ptr && t.Elem() == certPoolType {
+		if fv.IsNil() {
+			fv.Set(reflect.ValueOf(x509.NewCertPool()))
+		}
+		v := CertPool(fv.Interface().(*x509.CertPool))
+		switch format {
+		case "", "pem":
+			return v
+		case "file":
+			return &pemFileValue{v}
+		}
+		return nil
+	}
+	p := fv.Addr().Interface()
+	if newValue, ok := formats[t]; ok {
+		switch format {
+		case "", "pem":
+			return newValue(p)
+		case "file":
+			return &pemFileValue{newValue(p)}
+		}
+		return nil
+	}
+	if reflect.PtrTo(t).Implements(flagValueType) {
+		return p.(flag.Value)
+	}
+
+	if s, ok := p.(*Secret); ok {
+		switch format {
+		case "", "hex":
+			return SecretBytesHex(s)
+		case "base64":
+			return SecretBytesBase64(s)
+		case "file":
+			return SecretBytesFile(s)
+		}
+		return nil
+	}
+	if p, ok := p.(*[]byte); ok {
+		if format == "file" {
+			return &bytesFileValue{data: p}
+		}
+		if newValue, ok := byteFormats[format]; ok {
+			return newValue(p, *p)
+		}
+		return nil
+	}
+	if format != "" {
+		return nil
+	}
+
+	// The standard library values are borrowed from a throwaway flag set,
+	// boolean flags keeping their IsBoolFlag method.
+	tmp := flag.NewFlagSet("", flag.ContinueOnError)
+	switch p := p.(type) {
+	case *string:
+		tmp.StringVar(p, "v", *p, "")
+	case *bool:
+		tmp.BoolVar(p, "v", *p, "")
+	case *int:
+		tmp.IntVar(p, "v", *p, "")
+	case *int64:
+		tmp.Int64Var(p, "v", *p, "")
+	case *uint:
+		tmp.UintVar(p, "v", *p, "")
+	case *uint64:
+		tmp.Uint64Var(p, "v", *p, "")
+	case *float64:
+		tmp.Float64Var(p, "v", *p, "")
+	case *time.Duration:
+		tmp.DurationVar(p, "v", *p, "")
+	default:
+		return nil
+	}
+	return tmp.Lookup("v").Value
+}
+
+// pemFileValue adapts the value of a certificate or key for the file format:
+// values without a source prefix are taken as file paths rather than inline
+// PEM.
+type pemFileValue struct {
+	flag.Value
+}
+
+// String implements flag.Value.String.
+func (v pemFileValue) String() string {
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+// Set implements flag.Value.Set.
+func (v *pemFileValue) Set(value string) error {
+	if value != "" && !hasSourcePrefix(value) {
+		value = "file:" + value
+	}
+	return v.Value.Set(value)
+}
+
+// Get implements flag.Getter.
+func (v *pemFileValue) Get() interface{} {
+	return v.Value.(flag.Getter).Get()
+}
+
+// sourceRef implements reloadable.
+func (v *pemFileValue) sourceRef() string {
+	if r, ok := v.Value.(reloadable); ok {
+		return r.sourceRef()
+	}
+	return ""
+}
+
+// publicKey implements publicKeyHolder.
+func (v *pemFileValue) publicKey() crypto.PublicKey {
+	if h, ok := v.Value.(publicKeyHolder); ok {
+		return h.publicKey()
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package flagvars
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type registerConfig struct {
+	Cert   x509.Certificate `flag:"cert" usage:"server certificate"`
+	Roots  *x509.CertPool   `flag:"ca" usage:"client CAs"`
+	Pair   tls.Certificate  `flag:"pair"`
+	Key    []byte           `flag:"hmac-key" format:"base64" env:"TEST_REGISTER_HMAC_KEY"`
+	Seed   []byte           `flag:"seed"`
+	Signer ecdsa.PublicKey  `flag:"signer"`
+	DB     struct {
+		Password string        `flag:"password"`
+		Timeout  time.Duration `flag:"timeout"`
+	} `flag:"db"`
+	Embedded
+	Ignored string `flag:"-"`
+	NoTag   string
+}
+
+type Embedded struct {
+	Verbose bool `flag:"verbose"`
+}
+
+func TestRegister(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(generated.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	pubDer, err := x509.MarshalPKIXPublicKey(generated.Leaf.PublicKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+	pubPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer}))
+	t.Setenv("TEST_REGISTER_HMAC_KEY", "AQI=")
+
+	cfg := registerConfig{Seed: []byte{9}}
+	cfg.DB.Timeout = time.Second
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := Register(fs, &cfg); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	for _, name := range []string{"Ignored", "NoTag", "ignored"} {
+		if fs.Lookup(name) != nil {
+			t.Fatalf("expected field %s not to be registered", name)
+		}
+	}
+	if f := fs.Lookup("cert"); f == nil || f.Usage != "server certificate" {
+		t.Fatalf("expected -cert to be registered with its usage")
+	}
+
+	err = fs.Parse([]string{
+		"-cert", certPEM,
+		"-ca", certPEM,
+		"-pair", keyPEM + certPEM,
+		"-signer", pubPEM,
+		"-db-password", "secret",
+		"-verbose",
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	if !cfg.Cert.Equal(generated.Leaf) {
+		t.Fatalf("got: %v, expected %v", cfg.Cert.Subject, generated.Leaf.Subject)
+	}
+	if _, err := generated.Leaf.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: cfg.Roots}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if len(cfg.Pair.Certificate) != 1 {
+		t.Fatalf("expected key pair to be set")
+	}
+	if !cfg.Signer.Equal(generated.Leaf.PublicKey) {
+		t.Fatalf("expected public key to be set")
+	}
+	if !bytes.Equal(cfg.Key, []byte{1, 2}) || !bytes.Equal(cfg.Seed, []byte{9}) {
+		t.Fatalf("got: %X, %X, expected 0102, 09", cfg.Key, cfg.Seed)
+	}
+	if cfg.DB.Password != "secret" || cfg.DB.Timeout != time.Second || !cfg.Verbose {
+		t.Fatalf("got: %q, %v, %v, expected %q, %v, true", cfg.DB.Password, cfg.DB.Timeout, cfg.Verbose, "secret", time.Second)
+	}
+	if origins := Origins(fs); origins["hmac-key"] != OriginEnv {
+		t.Fatalf("got: %v, expected %v", origins["hmac-key"], OriginEnv)
+	}
+}
+
+func TestRegisterFileFormat(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(generated.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}), 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	var cfg struct {
+		Cert  x509.Certificate `flag:"cert" format:"file"`
+		Roots *x509.CertPool   `flag:"ca" format:"file"`
+		Key   crypto.Signer    `flag:"key" format:"file"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := Register(fs, &cfg); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	MatchKeyPair(fs, "key", "cert")
+	if err := fs.Parse([]string{"-cert", certPath, "-ca", "@" + certPath, "-key", keyPath}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := Validate(fs); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !cfg.Cert.Equal(generated.Leaf) {
+		t.Fatalf("got: %v, expected %v", cfg.Cert.Subject, generated.Leaf.Subject)
+	}
+	if _, err := generated.Leaf.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: cfg.Roots}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if fs.Lookup("cert").Value.String() != "file:"+certPath {
+		t.Fatalf("got: %q, expected %q", fs.Lookup("cert").Value.String(), "file:"+certPath)
+	}
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	if strings.Contains(usage.String(), "panic") {
+		t.Fatalf("got: %q, expected usage without panics", usage.String())
+	}
+}
+
+func TestRegisterFailures(t *testing.T) {
+	var cfg registerConfig
+	if err := Register(flag.NewFlagSet("test", flag.ContinueOnError), cfg); err == nil {
+		t.Fatalf("expected failure with non pointer")
+	}
+
+	var badFormat struct {
+		Cert x509.Certificate `flag:"cert" format:"hex"`
+	}
+	err := Register(flag.NewFlagSet("test", flag.ContinueOnError), &badFormat)
+	if err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Fatalf("got: %v, expected unknown format failure", err)
+	}
+
+	var badType struct {
+		Curve elliptic.Curve `flag:"curve"`
+	}
+	err = Register(flag.NewFlagSet("test", flag.ContinueOnError), &badType)
+	if err == nil || !strings.Contains(err.Error(), "unsupported type") {
+		t.Fatalf("got: %v, expected unsupported type failure", err)
+	}
+}
@@ -0,0 +1,83 @@
+package flagvars
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// reloadable is implemented by values which remember the source they were
+// read from. sourceRef returns an empty string for inline values.
+type reloadable interface {
+	sourceRef() string
+}
+
+// concurrentReloadable is implemented by values which are safe to set again
+// while in use, because their content is only read through accessors
+// synchronized with Set, such as KubernetesTLSSecret.Certificate.
+type concurrentReloadable interface {
+	reloadable
+	concurrentReload()
+}
+
+// Reload sets again every flag of fs whose value was read from a source,
+// picking up changes to files, environment variables or remote documents.
+// Values read from the standard input are left alone, it having been
+// consumed already. Values are updated in place, so nothing must read them
+// concurrently; calling Reload from the goroutine which uses them is the
+// simplest way to guarantee it.
+func Reload(fs *flag.FlagSet) error {
+	return reload(fs, false)
+}
+
+// reload implements Reload, skipping the values which are not safe to set
+// while in use if concurrent is true.
+func reload(fs *flag.FlagSet, concurrent bool) error {
+	var msgs []string
+	fs.VisitAll(func(f *flag.Flag) {
+		v, ok := f.Value.(reloadable)
+		if !ok {
+			return
+		}
+		if _, ok := v.(concurrentReloadable); concurrent && !ok {
+			return
+		}
+		ref := v.sourceRef()
+		if ref == "" || ref == "-" {
+			return
+		}
+		if err := f.Value.Set(ref); err != nil {
+			msgs = append(msgs, fmt.Sprintf("flag -%s: %v", f.Name, err))
+		}
+	})
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// Refresh reloads the flags of fs every interval until ctx is done. Since it
+// runs concurrently with the readers of the values, only the values which
+// are safe to set while in use are reloaded: TrustBundlePEM, TLSKeyPairPEM
+// and KubernetesTLSSecretDir, whose accessors and tls.Config hooks return
+// the latest content, and BytesFileLazy, which reads the file again on the
+// next call to LazyFile.Bytes. Other values must be reloaded with Reload
+// from the goroutine using them. Failures are reported to onError, if not
+// nil, and leave the failing values untouched.
+func Refresh(ctx context.Context, fs *flag.FlagSet, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := reload(fs, true); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
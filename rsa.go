@@ -1,29 +1,42 @@
 package flagvars
 
 import (
+	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"flag"
-	"strings"
 )
 
 // rsaPrivateKeyValue adapts rsa.PrivateKey for use as a flag. Value of flag
 // is PEM encoded.
 type rsaPrivateKeyValue struct {
-	dst *rsa.PrivateKey
+	dst    *rsa.PrivateKey
+	source string
 }
 
 // String implements flag.Value.String.
 func (v rsaPrivateKeyValue) String() string {
+	if StringFormat == FormatSummary {
+		if v.dst == nil || v.dst.N == nil {
+			return ""
+		}
+		return summarizePublicKey(&v.dst.PublicKey)
+	}
+	if v.source != "" {
+		return v.source
+	}
 	return "<redacted>"
 }
 
 // Set implements flag.Value.Set.
 func (v *rsaPrivateKeyValue) Set(value string) error {
-	value = strings.ReplaceAll(value, `\n`, "\n")
-	block, _ := pem.Decode([]byte(value))
+	data, source, err := resolvePrivate(value, 0)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
 	if block == nil || (block.Type != "PRIVATE KEY" && block.Type != "RSA PRIVATE KEY") {
 		return errors.New("failed to find a suitable pem block type")
 	}
@@ -33,13 +46,32 @@ func (v *rsaPrivateKeyValue) Set(value string) error {
 		return err
 	}
 	*v.dst = *priv
+	v.source = source
 
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*rsaPrivateKeyValue) Type() string {
-	return "rsaPrivateKey"
+	return TypeRSAPrivateKey
+}
+
+// Get implements flag.Getter. It returns a *rsa.PrivateKey.
+func (v rsaPrivateKeyValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *rsaPrivateKeyValue) sourceRef() string {
+	return v.source
+}
+
+// publicKey implements publicKeyHolder.
+func (v *rsaPrivateKeyValue) publicKey() crypto.PublicKey {
+	if v.dst.N == nil {
+		return nil
+	}
+	return &v.dst.PublicKey
 }
 
 // RSAPrivateKey creates and returns a new flag.Value compliant RSA
@@ -51,11 +83,15 @@ func RSAPrivateKey(p *rsa.PrivateKey) flag.Value {
 // rsaPublicKeyValue adapts rsa.PublicKey for use as a flag. Value of flag
 // is PEM encoded.
 type rsaPublicKeyValue struct {
-	dst *rsa.PublicKey
+	dst    *rsa.PublicKey
+	source string
 }
 
 // String implements flag.Value.String.
 func (v rsaPublicKeyValue) String() string {
+	if v.source != "" {
+		return v.source
+	}
 	publicKeyDer, _ := x509.MarshalPKIXPublicKey(v.dst)
 	return string(pem.EncodeToMemory(&pem.Block{
 		Type:  "PUBLIC KEY",
@@ -65,8 +101,11 @@ func (v rsaPublicKeyValue) String() string {
 
 // Set implements flag.Value.Set.
 func (v *rsaPublicKeyValue) Set(value string) error {
-	value = strings.ReplaceAll(value, `\n`, "\n")
-	block, _ := pem.Decode([]byte(value))
+	data, source, err := resolve(value)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
 	if block == nil || block.Type != "PUBLIC KEY" {
 		return errors.New("failed to find a suitable pem block type")
 	}
@@ -81,13 +120,32 @@ func (v *rsaPublicKeyValue) Set(value string) error {
 	default:
 		return errors.New("unknown type of public key")
 	}
+	v.source = source
 
 	return nil
 }
 
 // Type implements flag.Value.Type.
 func (*rsaPublicKeyValue) Type() string {
-	return "rsaPublicKey"
+	return TypeRSAPublicKey
+}
+
+// Get implements flag.Getter. It returns a *rsa.PublicKey.
+func (v rsaPublicKeyValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *rsaPublicKeyValue) sourceRef() string {
+	return v.source
+}
+
+// publicKey implements publicKeyHolder.
+func (v *rsaPublicKeyValue) publicKey() crypto.PublicKey {
+	if v.dst.N == nil {
+		return nil
+	}
+	return v.dst
 }
 
 // RSAPublicKey creates and returns a new flag.Value compliant RSA PublicKey
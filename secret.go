@@ -0,0 +1,218 @@
+package flagvars
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+)
+
+// Secret holds secret bytes, such as an HMAC key, which are never rendered
+// by String nor marshaled, and can be wiped from memory once no longer
+// needed. The zero value is empty and ready to use.
+//
+// Values given inline on the command line also live in the argument strings
+// of the process, which cannot be wiped; sources such as file: or env: avoid
+// that.
+type Secret struct {
+	// LockMemory asks for the bytes to be locked into memory, preventing
+	// them from being swapped out. Setting a value fails if they cannot be
+	// locked, which on Linux usually means RLIMIT_MEMLOCK is too low, and
+	// always happens on other platforms.
+	LockMemory bool
+
+	data   []byte
+	locked bool
+}
+
+// Bytes returns the secret bytes. The slice is wiped by Destroy and when a
+// new value is set, so it must not be retained.
+func (s *Secret) Bytes() []byte {
+	return s.data
+}
+
+// Len returns the length of the secret.
+func (s *Secret) Len() int {
+	return len(s.data)
+}
+
+// Equal reports whether the secret equals b in constant time, leaking only
+// whether their lengths differ.
+func (s *Secret) Equal(b []byte) bool {
+	return subtle.ConstantTimeCompare(s.data, b) == 1
+}
+
+// Destroy zeroes the secret bytes, unlocks them and empties s.
+func (s *Secret) Destroy() {
+	wipe(s.data)
+	if s.locked {
+		_ = munlock(s.data)
+	}
+	s.data, s.locked = nil, false
+}
+
+// replace destroys the current secret and holds data instead.
+func (s *Secret) replace(data []byte) error {
+	locked := false
+	if s.LockMemory && len(data) > 0 {
+		if err := mlock(data); err != nil {
+			wipe(data)
+			return err
+		}
+		locked = true
+	}
+	s.Destroy()
+	s.data, s.locked = data, locked
+	return nil
+}
+
+// String implements fmt.Stringer, redacting the secret.
+func (s Secret) String() string {
+	if len(s.data) == 0 {
+		return ""
+	}
+	return "<redacted>"
+}
+
+// GoString implements fmt.GoStringer, redacting the secret.
+func (Secret) GoString() string {
+	return "flagvars.Secret{<redacted>}"
+}
+
+// MarshalText implements encoding.TextMarshaler, refusing to marshal the
+// secret.
+func (Secret) MarshalText() ([]byte, error) {
+	return nil, ErrSecretMarshal
+}
+
+// MarshalJSON implements json.Marshaler, refusing to marshal the secret.
+func (Secret) MarshalJSON() ([]byte, error) {
+	return nil, ErrSecretMarshal
+}
+
+// wipe zeroes b.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// secretValue adapts Secret for use as a flag. Value of flag is decoded by
+// decode.
+type secretValue struct {
+	dst    *Secret
+	decode func(src []byte) ([]byte, error)
+	typ    string
+	source string
+}
+
+// String implements flag.Value.String. The secret itself is never rendered,
+// only the source it was read from.
+func (v secretValue) String() string {
+	if v.dst == nil {
+		return ""
+	}
+	if v.source != "" {
+		return v.source
+	}
+	return v.dst.String()
+}
+
+// Set implements flag.Value.Set. The previous secret is wiped, and so is
+// the encoded input unless a Resolver may return it again.
+func (v *secretValue) Set(value string) error {
+	raw, source, err := resolvePrivate(value, 0)
+	if err != nil {
+		return err
+	}
+	if ownsContent(value) {
+		defer wipe(raw)
+	}
+
+	data, err := v.decode(raw)
+	if err != nil {
+		return err
+	}
+	if err := v.dst.replace(data); err != nil {
+		return err
+	}
+	v.source = source
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (v *secretValue) Type() string {
+	return v.typ
+}
+
+// Get implements flag.Getter. It returns a *Secret.
+func (v secretValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *secretValue) sourceRef() string {
+	return v.source
+}
+
+// decodeHex decodes HEX encoded src into a new buffer.
+func decodeHex(src []byte) ([]byte, error) {
+	src = bytes.TrimSpace(src)
+	dst := make([]byte, hex.DecodedLen(len(src)))
+	if _, err := hex.Decode(dst, src); err != nil {
+		wipe(dst)
+		return nil, err
+	}
+	return dst, nil
+}
+
+// decodeBase64 decodes Base64 encoded src into a new buffer.
+func decodeBase64(src []byte) ([]byte, error) {
+	src = bytes.TrimSpace(src)
+	dst := make([]byte, base64.StdEncoding.DecodedLen(len(src)))
+	n, err := base64.StdEncoding.Decode(dst, src)
+	if err != nil {
+		wipe(dst)
+		return nil, err
+	}
+	return dst[:n], nil
+}
+
+// copyBytes copies src into a new buffer.
+func copyBytes(src []byte) ([]byte, error) {
+	return append([]byte(nil), src...), nil
+}
+
+// SecretBytesHex creates and returns a new flag.Value compliant hex secret
+// bytes parser.
+func SecretBytesHex(s *Secret) flag.Value {
+	return &secretValue{dst: s, decode: decodeHex, typ: TypeSecretBytesHex}
+}
+
+// SecretBytesBase64 creates and returns a new flag.Value compliant base64
+// secret bytes parser.
+func SecretBytesBase64(s *Secret) flag.Value {
+	return &secretValue{dst: s, decode: decodeBase64, typ: TypeSecretBytesBase64}
+}
+
+// SecretBytesFile creates and returns a new flag.Value compliant secret
+// bytes parser reading the content of a file, as BytesFile does. Values
+// without a source prefix are taken as a file path.
+func SecretBytesFile(s *Secret) flag.Value {
+	return &secretFileValue{secretValue{dst: s, decode: copyBytes, typ: TypeSecretBytesFile}}
+}
+
+// secretFileValue adapts Secret for use as a flag. Value of flag is a file
+// path or a source.
+type secretFileValue struct {
+	secretValue
+}
+
+// Set implements flag.Value.Set.
+func (v *secretFileValue) Set(value string) error {
+	if !hasSourcePrefix(value) {
+		value = "file:" + value
+	}
+	return v.secretValue.Set(value)
+}
@@ -0,0 +1,13 @@
+package flagvars
+
+import "syscall"
+
+// mlock locks b into memory.
+func mlock(b []byte) error {
+	return syscall.Mlock(b)
+}
+
+// munlock unlocks b.
+func munlock(b []byte) error {
+	return syscall.Munlock(b)
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package flagvars
+
+import "errors"
+
+// mlock fails, locking memory being only supported on Linux.
+func mlock([]byte) error {
+	return errors.New("locking memory is not supported on this platform")
+}
+
+// munlock does nothing.
+func munlock([]byte) error {
+	return nil
+}
@@ -0,0 +1,167 @@
+package flagvars
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hmac.key")
+	if err := ioutil.WriteFile(path, []byte{1, 2, 3}, 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	testCases := []struct {
+		newValue func(s *Secret) flag.Value
+		input    string
+		success  bool
+		expected []byte
+		str      string
+	}{
+		// Positive cases
+		{SecretBytesHex, "0102", true, []byte{1, 2}, "<redacted>"},
+		{SecretBytesBase64, "AQI=\n", true, []byte{1, 2}, "<redacted>"},
+		{SecretBytesFile, path, true, []byte{1, 2, 3}, "file:" + path},
+
+		// Negative cases
+		{SecretBytesHex, "zz", false, nil, ""},
+		{SecretBytesHex, "file:" + path + ".missing", false, nil, ""},
+		{SecretBytesBase64, "AQ", false, nil, ""},
+		{SecretBytesFile, path + ".missing", false, nil, ""},
+	}
+
+	for _, tc := range testCases {
+		var s Secret
+		v := tc.newValue(&s)
+		err := v.Set(tc.input)
+		if err != nil && tc.success {
+			t.Errorf("expected success, got %q", err)
+			continue
+		} else if err == nil && !tc.success {
+			t.Errorf("expected failure while processing %q", tc.input)
+			continue
+		} else if !tc.success {
+			continue
+		}
+		if !s.Equal(tc.expected) || v.String() != tc.str {
+			t.Errorf("got: %q, expected %X, %q", v.String(), tc.expected, tc.str)
+		}
+	}
+}
+
+func TestSecretRedaction(t *testing.T) {
+	var s Secret
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(SecretBytesHex(&s), "hmac-key", "HMAC key")
+	if err := fs.Parse([]string{"-hmac-key", "deadbeef"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.PrintDefaults()
+	fmt.Fprintf(&out, "%v %+v %#v %s", s, &s, &s, fs.Lookup("hmac-key").Value)
+	if strings.Contains(strings.ToLower(out.String()), "deadbeef") || strings.Contains(out.String(), "222 173 190 239") {
+		t.Fatalf("expected secret not to be rendered, got %q", out.String())
+	}
+	if _, err := json.Marshal(struct{ Key *Secret }{&s}); !errors.Is(err, ErrSecretMarshal) {
+		t.Fatalf("got: %v, expected %v", err, ErrSecretMarshal)
+	}
+}
+
+func TestSecretDestroy(t *testing.T) {
+	var s Secret
+	v := SecretBytesHex(&s)
+	if err := v.Set("0102"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	previous := s.Bytes()
+	if err := v.Set("030405"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(previous, []byte{0, 0}) {
+		t.Fatalf("got: %X, expected previous value to be wiped", previous)
+	}
+	if !s.Equal([]byte{3, 4, 5}) || s.Equal([]byte{3, 4}) || s.Len() != 3 {
+		t.Fatalf("got: %X, expected 030405", s.Bytes())
+	}
+
+	current := s.Bytes()
+	s.Destroy()
+	if !bytes.Equal(current, []byte{0, 0, 0}) || s.Len() != 0 || v.String() != "" {
+		t.Fatalf("got: %X, %q, expected value to be wiped", current, v.String())
+	}
+}
+
+func TestSecretLockMemory(t *testing.T) {
+	s := Secret{LockMemory: true}
+	if err := SecretBytesHex(&s).Set("0102"); err != nil {
+		t.Skipf("locking memory is not permitted: %v", err)
+	}
+	if !s.locked {
+		t.Fatalf("expected memory to be locked")
+	}
+	s.Destroy()
+	if s.locked {
+		t.Fatalf("expected memory to be unlocked")
+	}
+}
+
+func TestSecretBytesSharedSource(t *testing.T) {
+	mem := MemoryResolver{"hmac": []byte("0102")}
+	RegisterResolver("mem", mem)
+	RegisterResolver("cached", CachedResolver(MemoryResolver{"hmac": []byte("AQI=")}, 0))
+	defer RegisterResolver("mem", nil)
+	defer RegisterResolver("cached", nil)
+
+	testCases := []struct {
+		newValue func(s *Secret) flag.Value
+		input    string
+	}{
+		{SecretBytesHex, "mem:hmac"},
+		{SecretBytesBase64, "cached:hmac"},
+	}
+	for _, tc := range testCases {
+		for i := 0; i < 2; i++ {
+			var s Secret
+			if err := tc.newValue(&s).Set(tc.input); err != nil {
+				t.Fatalf("expected success, got %q", err)
+			}
+			if !s.Equal([]byte{1, 2}) {
+				t.Fatalf("got: %X, expected 0102", s.Bytes())
+			}
+		}
+	}
+	if string(mem["hmac"]) != "0102" {
+		t.Fatalf("got: %q, expected resolver content to be left alone", mem["hmac"])
+	}
+}
+
+func TestSecretBytesPrintDefaults(t *testing.T) {
+	var s Secret
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(SecretBytesHex(&s), "hex", "hex secret")
+	fs.Var(SecretBytesBase64(&s), "base64", "base64 secret")
+	fs.Var(SecretBytesFile(&s), "file", "secret file")
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	if strings.Contains(usage.String(), "panic") || strings.Contains(usage.String(), "default") {
+		t.Fatalf("got: %q, expected no default", usage.String())
+	}
+}
+
+func TestSecretBytesVar(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	SecretBytesHexFlag(fs, "hmac-key", "HMAC key")
+	SecretBytesBase64Flag(fs, "api-key", "API key")
+	SecretBytesFileFlag(fs, "seed-file", "", "seed file")
+}
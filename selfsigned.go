@@ -0,0 +1,226 @@
+package flagvars
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+)
+
+// selfSignedPrefix marks a TLS certificate flag value as a request to
+// generate an ephemeral self-signed certificate.
+const selfSignedPrefix = "self-signed:"
+
+// KeyType identifies the algorithm and size of a generated private key.
+type KeyType int
+
+const (
+	// ECDSAP256 generates an ECDSA key on the NIST P-256 curve.
+	ECDSAP256 KeyType = iota
+	// ECDSAP384 generates an ECDSA key on the NIST P-384 curve.
+	ECDSAP384
+	// RSA2048 generates a 2048 bit RSA key.
+	RSA2048
+	// RSA4096 generates a 4096 bit RSA key.
+	RSA4096
+)
+
+// String implements fmt.Stringer.
+func (t KeyType) String() string {
+	switch t {
+	case ECDSAP256:
+		return "ECDSA P-256"
+	case ECDSAP384:
+		return "ECDSA P-384"
+	case RSA2048:
+		return "RSA 2048"
+	case RSA4096:
+		return "RSA 4096"
+	}
+	return fmt.Sprintf("KeyType(%d)", int(t))
+}
+
+// generateKey creates a fresh private key of the given type.
+func generateKey(t KeyType) (crypto.Signer, error) {
+	switch t {
+	case ECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case ECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case RSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case RSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	}
+	return nil, fmt.Errorf("unknown key type %v", t)
+}
+
+// randomSerialNumber returns a random 128 bit certificate serial number.
+func randomSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// SelfSignedOptions configures the ephemeral certificates generated by
+// TLSCertificateSelfSigned.
+type SelfSignedOptions struct {
+	// Lifetime is the validity period of the generated certificate. Defaults
+	// to 24 hours.
+	Lifetime time.Duration
+
+	// KeyType selects the algorithm of the generated key. Defaults to
+	// ECDSAP256.
+	KeyType KeyType
+
+	// CAFile, if not empty, is the path where the PEM encoded certificate is
+	// written so that clients can trust it.
+	CAFile string
+
+	// CAWriter, if not nil, receives the PEM encoded certificate.
+	CAWriter io.Writer
+}
+
+// selfSignedTLSCertificateValue adapts tls.Certificate for use as a flag.
+// Value of flag is either PEM encoded or a self-signed:host,... generator.
+type selfSignedTLSCertificateValue struct {
+	tlsCertificateValue
+	opts  SelfSignedOptions
+	hosts string
+}
+
+// String implements flag.Value.String.
+func (v selfSignedTLSCertificateValue) String() string {
+	if v.hosts != "" {
+		return selfSignedPrefix + v.hosts
+	}
+	return v.tlsCertificateValue.String()
+}
+
+// Set implements flag.Value.Set.
+func (v *selfSignedTLSCertificateValue) Set(value string) error {
+	if !strings.HasPrefix(value, selfSignedPrefix) {
+		if err := v.tlsCertificateValue.Set(value); err != nil {
+			return err
+		}
+		v.hosts = ""
+		return nil
+	}
+
+	hosts := strings.TrimPrefix(value, selfSignedPrefix)
+	cert, err := generateSelfSigned(strings.Split(hosts, ","), v.opts)
+	if err != nil {
+		return err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: cert.Certificate[0],
+	})
+	if v.opts.CAFile != "" {
+		if err := ioutil.WriteFile(v.opts.CAFile, certPEM, 0644); err != nil {
+			return err
+		}
+	}
+	if v.opts.CAWriter != nil {
+		if _, err := v.opts.CAWriter.Write(certPEM); err != nil {
+			return err
+		}
+	}
+
+	*v.dst = *cert
+	v.source = ""
+	v.hosts = hosts
+	return nil
+}
+
+// generateSelfSigned creates a key and a self-signed certificate valid for
+// the given DNS names and IP addresses.
+func generateSelfSigned(hosts []string, opts SelfSignedOptions) (*tls.Certificate, error) {
+	template := &x509.Certificate{
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth,
+			x509.ExtKeyUsageClientAuth,
+		},
+	}
+	for _, h := range hosts {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+		if template.Subject.CommonName == "" {
+			template.Subject = pkix.Name{CommonName: h}
+		}
+	}
+	if template.Subject.CommonName == "" {
+		return nil, errors.New("self-signed certificate requires at least one host")
+	}
+
+	lifetime := opts.Lifetime
+	if lifetime <= 0 {
+		lifetime = 24 * time.Hour
+	}
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = template.NotBefore.Add(lifetime)
+
+	serial, err := randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serial
+
+	key, err := generateKey(opts.KeyType)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// TLSCertificateSelfSigned creates and returns a new flag.Value compliant
+// X509KeyPair parser which additionally accepts a self-signed:host,...
+// value. Such a value generates a fresh key and a self-signed certificate
+// for the given hosts at parse time. It is meant for development only and
+// must be explicitly chosen over TLSCertificate.
+func TLSCertificateSelfSigned(c *tls.Certificate, opts SelfSignedOptions) flag.Value {
+	return &selfSignedTLSCertificateValue{
+		tlsCertificateValue: tlsCertificateValue{dst: c},
+		opts:                opts,
+	}
+}
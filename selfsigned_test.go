@@ -0,0 +1,86 @@
+package flagvars
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestTLSCertificateSelfSigned(t *testing.T) {
+	var buf bytes.Buffer
+	var gotCert tls.Certificate
+	certValue := TLSCertificateSelfSigned(&gotCert, SelfSignedOptions{
+		Lifetime: time.Hour,
+		CAWriter: &buf,
+	})
+	if err := certValue.Set("self-signed:localhost,127.0.0.1"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	if _, ok := gotCert.PrivateKey.(*ecdsa.PrivateKey); !ok {
+		t.Fatalf("got: %T, expected %T", gotCert.PrivateKey, &ecdsa.PrivateKey{})
+	}
+
+	leaf := gotCert.Leaf
+	if len(leaf.DNSNames) != 1 || leaf.DNSNames[0] != "localhost" {
+		t.Fatalf("got: %v, expected %v", leaf.DNSNames, []string{"localhost"})
+	}
+	if len(leaf.IPAddresses) != 1 || !leaf.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")) {
+		t.Fatalf("got: %v, expected %v", leaf.IPAddresses, []string{"127.0.0.1"})
+	}
+	if lifetime := leaf.NotAfter.Sub(leaf.NotBefore); lifetime != time.Hour {
+		t.Fatalf("got: %v, expected %v", lifetime, time.Hour)
+	}
+
+	block, _ := pem.Decode(buf.Bytes())
+	if block == nil || !bytes.Equal(block.Bytes, leaf.Raw) {
+		t.Fatalf("expected generated certificate to be written")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(buf.Bytes())
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: pool}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	if got := certValue.String(); got != "self-signed:localhost,127.0.0.1" {
+		t.Fatalf("got: %q, expected %q", got, "self-signed:localhost,127.0.0.1")
+	}
+}
+
+func TestTLSCertificateSelfSignedKeyType(t *testing.T) {
+	var gotCert tls.Certificate
+	certValue := TLSCertificateSelfSigned(&gotCert, SelfSignedOptions{KeyType: RSA2048})
+	if err := certValue.Set("self-signed:localhost"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	if _, ok := gotCert.PrivateKey.(*rsa.PrivateKey); !ok {
+		t.Fatalf("got: %T, expected %T", gotCert.PrivateKey, &rsa.PrivateKey{})
+	}
+}
+
+func TestTLSCertificateSelfSignedOptIn(t *testing.T) {
+	var gotCert tls.Certificate
+	if err := TLSCertificate(&gotCert).Set("self-signed:localhost"); err == nil {
+		t.Fatalf("expected failure while processing %q", "self-signed:localhost")
+	}
+
+	certValue := TLSCertificateSelfSigned(&gotCert, SelfSignedOptions{})
+	if err := certValue.Set("self-signed:"); err == nil {
+		t.Fatalf("expected failure while processing %q", "self-signed:")
+	}
+}
+
+func TestTLSCertificateSelfSignedVar(t *testing.T) {
+	var tlsCert tls.Certificate
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Var(TLSCertificateSelfSigned(&tlsCert, SelfSignedOptions{}), "tls-cert", "TLS certificate")
+}
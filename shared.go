@@ -0,0 +1,230 @@
+package flagvars
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"sync"
+)
+
+// TrustBundle holds CA certificates, such as a bundle fetched over https,
+// which Refresh can replace while they are in use. It is safe for
+// concurrent use. As tls.Config.RootCAs and ClientCAs are read without
+// synchronization, connections pick up refreshed bundles through
+// VerifyServer or VerifyClient instead.
+type TrustBundle struct {
+	mu     sync.RWMutex
+	source string
+	certs  []*x509.Certificate
+	pool   *x509.CertPool
+}
+
+// CertPool returns the current certificates as a pool, or nil before the
+// bundle is loaded. The pool must not be modified.
+func (b *TrustBundle) CertPool() *x509.CertPool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.pool
+}
+
+// Certificates returns the current certificates.
+func (b *TrustBundle) Certificates() []*x509.Certificate {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.certs
+}
+
+// VerifyServer can be used as tls.Config.VerifyConnection of clients, along
+// with InsecureSkipVerify, so that servers are verified against the current
+// bundle, including their name.
+func (b *TrustBundle) VerifyServer(cs tls.ConnectionState) error {
+	return b.verify(cs, cs.ServerName, x509.ExtKeyUsageServerAuth)
+}
+
+// VerifyClient can be used as tls.Config.VerifyConnection of servers, along
+// with ClientAuth set to tls.RequireAnyClientCert, so that client
+// certificates are verified against the current bundle.
+func (b *TrustBundle) VerifyClient(cs tls.ConnectionState) error {
+	return b.verify(cs, "", x509.ExtKeyUsageClientAuth)
+}
+
+// verify checks the peer certificates of cs against the current bundle.
+func (b *TrustBundle) verify(cs tls.ConnectionState, name string, usage x509.ExtKeyUsage) error {
+	pool := b.CertPool()
+	if pool == nil {
+		return errors.New("trust bundle is not loaded")
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("missing peer certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       name,
+		Roots:         pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	return err
+}
+
+// trustBundleValue adapts TrustBundle for use as a flag. Value of flag is
+// PEM encoded.
+type trustBundleValue struct {
+	dst *TrustBundle
+}
+
+// String implements flag.Value.String.
+func (v trustBundleValue) String() string {
+	if v.dst == nil {
+		return ""
+	}
+	v.dst.mu.RLock()
+	defer v.dst.mu.RUnlock()
+	if v.dst.source != "" && StringFormat != FormatSummary {
+		return v.dst.source
+	}
+	return formatCertificates(v.dst.certs)
+}
+
+// Set implements flag.Value.Set. Certificates are parsed as CertPool does
+// and replace the previous ones at once.
+func (v *trustBundleValue) Set(value string) error {
+	parsed := &certPoolValue{dst: new(x509.CertPool)}
+	if err := parsed.Set(value); err != nil {
+		return err
+	}
+
+	v.dst.mu.Lock()
+	defer v.dst.mu.Unlock()
+	v.dst.source, v.dst.certs, v.dst.pool = parsed.source, parsed.certs, parsed.dst
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (*trustBundleValue) Type() string {
+	return TypeTrustBundle
+}
+
+// Get implements flag.Getter. It returns a *TrustBundle.
+func (v trustBundleValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *trustBundleValue) sourceRef() string {
+	v.dst.mu.RLock()
+	defer v.dst.mu.RUnlock()
+	return v.dst.source
+}
+
+// concurrentReload implements concurrentReloadable.
+func (*trustBundleValue) concurrentReload() {}
+
+// TrustBundlePEM creates and returns a new flag.Value compliant parser of
+// PEM encoded CA certificates which Refresh keeps up to date.
+func TrustBundlePEM(b *TrustBundle) flag.Value {
+	return &trustBundleValue{dst: b}
+}
+
+// TLSKeyPair holds a key pair, such as one read from a file, which Refresh
+// can replace while it is in use. It is safe for concurrent use.
+type TLSKeyPair struct {
+	mu     sync.RWMutex
+	source string
+	cert   *tls.Certificate
+}
+
+// Certificate returns the current key pair, or nil before it is loaded.
+func (k *TLSKeyPair) Certificate() *tls.Certificate {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.cert
+}
+
+// GetCertificate can be used as tls.Config.GetCertificate so that refreshed
+// key pairs are picked up by new connections.
+func (k *TLSKeyPair) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cert := k.Certificate(); cert != nil {
+		return cert, nil
+	}
+	return nil, errors.New("tls key pair is not loaded")
+}
+
+// GetClientCertificate can be used as tls.Config.GetClientCertificate so
+// that refreshed key pairs are picked up by new connections.
+func (k *TLSKeyPair) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return k.GetCertificate(nil)
+}
+
+// tlsKeyPairValue adapts TLSKeyPair for use as a flag. Value of flag is PEM
+// encoded, the private key first, as for TLSCertificate.
+type tlsKeyPairValue struct {
+	dst *TLSKeyPair
+}
+
+// String implements flag.Value.String.
+func (v tlsKeyPairValue) String() string {
+	if v.dst == nil {
+		return ""
+	}
+	v.dst.mu.RLock()
+	defer v.dst.mu.RUnlock()
+	if v.dst.cert == nil {
+		return ""
+	}
+	return tlsCertificateValue{dst: v.dst.cert, source: v.dst.source}.String()
+}
+
+// Set implements flag.Value.Set. The key pair is parsed as TLSCertificate
+// does and replaces the previous one at once.
+func (v *tlsKeyPairValue) Set(value string) error {
+	parsed := &tlsCertificateValue{dst: new(tls.Certificate)}
+	if err := parsed.Set(value); err != nil {
+		return err
+	}
+
+	v.dst.mu.Lock()
+	defer v.dst.mu.Unlock()
+	v.dst.source, v.dst.cert = parsed.source, parsed.dst
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (*tlsKeyPairValue) Type() string {
+	return TypeTLSKeyPair
+}
+
+// Get implements flag.Getter. It returns a *TLSKeyPair.
+func (v tlsKeyPairValue) Get() interface{} {
+	return v.dst
+}
+
+// sourceRef implements reloadable.
+func (v *tlsKeyPairValue) sourceRef() string {
+	v.dst.mu.RLock()
+	defer v.dst.mu.RUnlock()
+	return v.dst.source
+}
+
+// publicKey implements publicKeyHolder.
+func (v *tlsKeyPairValue) publicKey() crypto.PublicKey {
+	cert := v.dst.Certificate()
+	if cert == nil {
+		return nil
+	}
+	return (&tlsCertificateValue{dst: cert}).publicKey()
+}
+
+// concurrentReload implements concurrentReloadable.
+func (*tlsKeyPairValue) concurrentReload() {}
+
+// TLSKeyPairPEM creates and returns a new flag.Value compliant parser of
+// PEM encoded key pairs which Refresh keeps up to date.
+func TLSKeyPairPEM(k *TLSKeyPair) flag.Value {
+	return &tlsKeyPairValue{dst: k}
+}
@@ -0,0 +1,114 @@
+package flagvars
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrustBundle(t *testing.T) {
+	first, err := generateSelfSigned([]string{"first.local"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	second, err := generateSelfSigned([]string{"second.local"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	firstPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: first.Leaf.Raw}))
+	secondPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: second.Leaf.Raw}))
+
+	doc := &testDocument{body: firstPEM}
+	server, _ := newTestServer(t, doc)
+
+	var bundle TrustBundle
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(TrustBundlePEM(&bundle), "ca", "CA bundle")
+	if err := fs.Parse([]string{"-ca", server.URL}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if fs.Lookup("ca").Value.String() != server.URL {
+		t.Fatalf("got: %q, expected %q", fs.Lookup("ca").Value.String(), server.URL)
+	}
+
+	firstConn := tls.ConnectionState{ServerName: "first.local", PeerCertificates: []*x509.Certificate{first.Leaf}}
+	secondConn := tls.ConnectionState{ServerName: "second.local", PeerCertificates: []*x509.Certificate{second.Leaf}}
+	if err := bundle.VerifyServer(firstConn); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := bundle.VerifyServer(tls.ConnectionState{ServerName: "other.local", PeerCertificates: firstConn.PeerCertificates}); err == nil {
+		t.Fatalf("expected failure with mismatching server name")
+	}
+	if err := bundle.VerifyServer(secondConn); err == nil {
+		t.Fatalf("expected failure with untrusted certificate")
+	}
+
+	doc.update(secondPEM)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go Refresh(ctx, fs, time.Millisecond, nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for bundle.VerifyServer(secondConn) != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected trust bundle to be refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if certs := bundle.Certificates(); len(certs) != 1 || !certs[0].Equal(second.Leaf) {
+		t.Fatalf("expected refreshed certificates")
+	}
+}
+
+func TestTLSKeyPair(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(generated.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	pairPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+
+	var pair TLSKeyPair
+	if _, err := pair.GetCertificate(nil); err == nil {
+		t.Fatalf("expected failure before the key pair is loaded")
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(TLSKeyPairPEM(&pair), "tls", "key pair")
+	MatchKeyPair(fs, "tls", "tls")
+	if err := Parse(fs, []string{"-tls", pairPEM}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	cert, err := pair.GetClientCertificate(nil)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(cert.Certificate[0], generated.Leaf.Raw) {
+		t.Fatalf("expected key pair to be loaded")
+	}
+	if fs.Lookup("tls").Value.String() != "<redacted>" {
+		t.Fatalf("got: %q, expected key pair to be redacted", fs.Lookup("tls").Value.String())
+	}
+}
+
+func TestSharedPrintDefaults(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	TrustBundleFlag(fs, "ca", "", "CA bundle")
+	TLSKeyPairFlag(fs, "tls", "", "key pair")
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	if strings.Contains(usage.String(), "panic") || strings.Contains(usage.String(), "default") {
+		t.Fatalf("got: %q, expected no default", usage.String())
+	}
+}
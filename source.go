@@ -0,0 +1,323 @@
+package flagvars
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stdin is where the "-" source reads from.
+var stdin io.Reader = os.Stdin
+
+// ResolveTimeout bounds the time a Resolver may take to fetch the content of
+// a single flag value.
+var ResolveTimeout = 30 * time.Second
+
+// Resolver fetches the content referenced by flag values of the form
+// scheme:ref, such as vault://secret/data/app#key. Resolve receives the part
+// following the scheme and its colon, "//secret/data/app#key" in the
+// example above. Resolvers may return the same buffer more than once, as
+// MemoryResolver and CachedResolver do, so callers must not modify it.
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) ([]byte, error)
+}
+
+// ResolverFunc adapts an ordinary function for use as a Resolver.
+type ResolverFunc func(ctx context.Context, ref string) ([]byte, error)
+
+// Resolve implements Resolver.Resolve.
+func (f ResolverFunc) Resolve(ctx context.Context, ref string) ([]byte, error) {
+	return f(ctx, ref)
+}
+
+// resolvers holds the registered resolvers by scheme.
+var resolvers = struct {
+	sync.RWMutex
+	m map[string]Resolver
+}{m: map[string]Resolver{
+	"file":       FileResolver{},
+	"env":        ResolverFunc(resolveEnv),
+	"https":      &HTTPResolver{},
+	"credential": CredentialResolver{},
+	"secret":     DockerSecretResolver{},
+}}
+
+// RegisterResolver makes r handle every flag value prefixed by scheme and a
+// colon. Registering a nil Resolver removes the scheme. The file, env,
+// https, credential and secret schemes are registered by default.
+func RegisterResolver(scheme string, r Resolver) {
+	resolvers.Lock()
+	defer resolvers.Unlock()
+	if r == nil {
+		delete(resolvers.m, scheme)
+		return
+	}
+	resolvers.m[scheme] = r
+}
+
+// lookupResolver returns the resolver registered for the scheme value starts
+// with, along with the scheme and the reference following it.
+func lookupResolver(value string) (Resolver, string, string, bool) {
+	i := strings.IndexByte(value, ':')
+	if i <= 0 || !validScheme(value[:i]) {
+		return nil, "", "", false
+	}
+	resolvers.RLock()
+	defer resolvers.RUnlock()
+	r, ok := resolvers.m[value[:i]]
+	return r, value[:i], value[i+1:], ok
+}
+
+// validScheme reports whether s is a syntactically valid URI scheme.
+func validScheme(s string) bool {
+	for i, c := range s {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Resolve returns the content a flag value refers to. Every value in this
+// package accepts the following forms:
+//
+//	scheme:ref     the content fetched by the Resolver registered for scheme
+//	file:path      the content of the file at path
+//	@path          shorthand for file:path
+//	env:VAR        the content of the environment variable VAR
+//	https://url    the body fetched from url
+//	credential:ID  the systemd credential ID
+//	secret:NAME    the Docker secret NAME
+//	-              the content of the standard input
+//	literal:value  value itself, even if it looks like one of the above
+//
+// Any other value is taken inline. Escaped `\n` sequences in inline and
+// environment values are turned into newlines so that PEM can be passed on a
+// single line.
+func Resolve(ctx context.Context, value string) ([]byte, error) {
+	data, _, err := resolveContext(ctx, value)
+	return data, err
+}
+
+// resolve is like Resolve but bounded by ResolveTimeout. The returned source
+// is empty for inline values; otherwise it is the original value, which
+// values report through String instead of their content.
+func resolve(value string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ResolveTimeout)
+	defer cancel()
+	return resolveContext(ctx, value)
+}
+
+// resolveContext implements Resolve.
+func resolveContext(ctx context.Context, value string) ([]byte, string, error) {
+	switch {
+	case value == "-":
+		data, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read standard input: %v", err)
+		}
+		return data, value, nil
+	case strings.HasPrefix(value, "@"):
+		data, _, err := resolveContext(ctx, "file:"+strings.TrimPrefix(value, "@"))
+		if err != nil {
+			return nil, "", err
+		}
+		return data, value, nil
+	case strings.HasPrefix(value, "literal:"):
+		value = strings.TrimPrefix(value, "literal:")
+		return []byte(strings.ReplaceAll(value, `\n`, "\n")), "", nil
+	}
+
+	if r, scheme, ref, ok := lookupResolver(value); ok {
+		data, err := r.Resolve(ctx, ref)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to resolve %s source: %v", scheme, err)
+		}
+		return data, value, nil
+	}
+	if i := strings.Index(value, "://"); i > 0 && validScheme(value[:i]) {
+		return nil, "", fmt.Errorf("no resolver registered for scheme %s", value[:i])
+	}
+
+	return []byte(strings.ReplaceAll(value, `\n`, "\n")), "", nil
+}
+
+// resolvePrivate is like resolve for values holding private material: it
+// refuses files failing the checks of KeyFilePermissions, and fails when the
+// content is larger than max bytes, if positive. Files read by a FileResolver
+// are not read past the limit.
+func resolvePrivate(value string, max int64) ([]byte, string, error) {
+	lookup := value
+	if strings.HasPrefix(value, "@") {
+		lookup = "file:" + strings.TrimPrefix(value, "@")
+	}
+	if r, scheme, ref, ok := lookupResolver(lookup); ok {
+		if fr, ok := r.(FileResolver); ok {
+			data, err := fr.read(ref, max)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to resolve %s source: %v", scheme, err)
+			}
+			return data, value, nil
+		}
+	}
+
+	data, source, err := resolve(value)
+	if err != nil {
+		return nil, "", err
+	}
+	if max > 0 && int64(len(data)) > max {
+		return nil, "", fmt.Errorf("content is larger than %d bytes", max)
+	}
+	return data, source, nil
+}
+
+// ownsContent reports whether the content resolved from value is a buffer
+// allocated for the caller, which may then modify it, rather than one a
+// Resolver may return again.
+func ownsContent(value string) bool {
+	if strings.HasPrefix(value, "@") {
+		value = "file:" + strings.TrimPrefix(value, "@")
+	}
+	r, _, _, ok := lookupResolver(value)
+	if !ok {
+		return true
+	}
+	_, ok = r.(FileResolver)
+	return ok
+}
+
+// hasSourcePrefix reports whether value refers to its content through one of
+// the forms understood by Resolve rather than holding it inline.
+func hasSourcePrefix(value string) bool {
+	if value == "-" || strings.HasPrefix(value, "@") || strings.HasPrefix(value, "literal:") {
+		return true
+	}
+	_, _, _, ok := lookupResolver(value)
+	return ok
+}
+
+// resolveEnv implements the env scheme.
+func resolveEnv(_ context.Context, name string) ([]byte, error) {
+	data, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	return []byte(strings.ReplaceAll(data, `\n`, "\n")), nil
+}
+
+// FileResolver resolves references as file paths. It handles the file
+// scheme by default.
+type FileResolver struct {
+	// Dir, if not empty, is the directory relative paths are resolved
+	// against.
+	Dir string
+}
+
+// Resolve implements Resolver.Resolve.
+func (r FileResolver) Resolve(_ context.Context, path string) ([]byte, error) {
+	return ioutil.ReadFile(r.path(path))
+}
+
+// path returns path relative to r.Dir.
+func (r FileResolver) path(path string) string {
+	if r.Dir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(r.Dir, path)
+	}
+	return path
+}
+
+// read reads the file at path, which holds private material, once checked
+// against KeyFilePermissions, failing without reading it past max bytes, if
+// positive, when it is larger.
+func (r FileResolver) read(path string, max int64) ([]byte, error) {
+	path = r.path(path)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKeyFile(path, fi); err != nil {
+		return nil, err
+	}
+
+	if max <= 0 {
+		return ioutil.ReadAll(f)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(f, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("%s is larger than %d bytes", path, max)
+	}
+	return data, nil
+}
+
+// MemoryResolver resolves references from an in-memory map, which is
+// convenient to exercise flags in tests.
+type MemoryResolver map[string][]byte
+
+// Resolve implements Resolver.Resolve.
+func (r MemoryResolver) Resolve(_ context.Context, ref string) ([]byte, error) {
+	data, ok := r[ref]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", ref)
+	}
+	return data, nil
+}
+
+// cachedResolver implements CachedResolver.
+type cachedResolver struct {
+	r   Resolver
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is a previously resolved content.
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// CachedResolver wraps r so that each reference is fetched at most once per
+// ttl. A non-positive ttl caches references forever.
+func CachedResolver(r Resolver, ttl time.Duration) Resolver {
+	return &cachedResolver{r: r, ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// Resolve implements Resolver.Resolve.
+func (c *cachedResolver) Resolve(ctx context.Context, ref string) ([]byte, error) {
+	c.mu.Lock()
+	e, ok := c.entries[ref]
+	c.mu.Unlock()
+	if ok && (c.ttl <= 0 || time.Now().Before(e.expires)) {
+		return e.data, nil
+	}
+
+	data, err := c.r.Resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[ref] = cacheEntry{data: data, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return data, nil
+}
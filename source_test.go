@@ -0,0 +1,229 @@
+package flagvars
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "value")
+	if err := ioutil.WriteFile(path, []byte("from file\n"), 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	t.Setenv("FLAGVARS_TEST_VALUE", `from\nenv`)
+
+	previous := stdin
+	stdin = strings.NewReader("from stdin")
+	defer func() { stdin = previous }()
+
+	testCases := []struct {
+		input    string
+		success  bool
+		expected string
+		source   string
+	}{
+		/// Positive cases
+		{`inline\nvalue`, true, "inline\nvalue", ""},
+		{"@" + path, true, "from file\n", "@" + path},
+		{"file:" + path, true, "from file\n", "file:" + path},
+		{"env:FLAGVARS_TEST_VALUE", true, "from\nenv", "env:FLAGVARS_TEST_VALUE"},
+		{"-", true, "from stdin", "-"},
+		{"literal:@" + path, true, "@" + path, ""},
+
+		// Negative cases
+		{"file:" + filepath.Join(dir, "missing"), false, "", ""},
+		{"env:FLAGVARS_TEST_MISSING", false, "", ""},
+	}
+
+	for _, tc := range testCases {
+		data, source, err := resolve(tc.input)
+		if err != nil && tc.success == true {
+			t.Errorf("expected success, got %q", err)
+			continue
+		} else if err == nil && tc.success == false {
+			t.Errorf("expected failure while processing %q", tc.input)
+			continue
+		} else if tc.success {
+			if string(data) != tc.expected || source != tc.source {
+				t.Errorf("got: %q from %q, expected %q from %q", data, source, tc.expected, tc.source)
+			}
+		}
+	}
+}
+
+func TestSourceValues(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{KeyType: RSA2048})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(generated.PrivateKey.(*rsa.PrivateKey)),
+	})
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw})
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	if err := ioutil.WriteFile(certPath, certPEM, 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	t.Setenv("FLAGVARS_TEST_KEY", strings.ReplaceAll(string(keyPEM), "\n", `\n`))
+	t.Setenv("FLAGVARS_TEST_HEX", "0102")
+
+	var cert x509.Certificate
+	certValue := Certificate(&cert)
+	if err := certValue.Set("@" + certPath); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !cert.Equal(generated.Leaf) || certValue.String() != "@"+certPath {
+		t.Fatalf("got: %q, expected certificate from %q", certValue.String(), "@"+certPath)
+	}
+
+	var priv rsa.PrivateKey
+	privValue := RSAPrivateKey(&priv)
+	if err := privValue.Set("env:FLAGVARS_TEST_KEY"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !priv.Equal(generated.PrivateKey) || privValue.String() != "env:FLAGVARS_TEST_KEY" {
+		t.Fatalf("got: %q, expected key from %q", privValue.String(), "env:FLAGVARS_TEST_KEY")
+	}
+
+	var b []byte
+	hexValue := BytesHex(&b, nil)
+	if err := hexValue.Set("env:FLAGVARS_TEST_HEX"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(b, []byte{1, 2}) || hexValue.String() != "env:FLAGVARS_TEST_HEX" {
+		t.Fatalf("got: %X from %q, expected 0102", b, hexValue.String())
+	}
+	if err := hexValue.Set("0a0b"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if hexValue.String() != "0A0B" {
+		t.Fatalf("got: %q, expected %q", hexValue.String(), "0A0B")
+	}
+
+	fileValue := BytesFile(&b, "")
+	if err := fileValue.Set("env:FLAGVARS_TEST_HEX"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if string(b) != "0102" {
+		t.Fatalf("got: %q, expected %q", b, "0102")
+	}
+}
+
+func TestHasSourcePrefix(t *testing.T) {
+	for value, expected := range map[string]bool{
+		"-":                           true,
+		"@path":                       true,
+		"file:path":                   true,
+		"env:VAR":                     true,
+		"literal:value":               true,
+		"path":                        false,
+		"-----BEGIN CERTIFICATE-----": false,
+		"":                            false,
+	} {
+		if got := hasSourcePrefix(value); got != expected {
+			t.Errorf("got: %v, expected %v for %q", got, expected, value)
+		}
+	}
+}
+
+func TestRegisterResolver(t *testing.T) {
+	RegisterResolver("vault", MemoryResolver{
+		"//secret/data/app#key": []byte("0102"),
+	})
+	defer RegisterResolver("vault", nil)
+
+	var b []byte
+	hexValue := BytesHex(&b, nil)
+	if err := hexValue.Set("vault://secret/data/app#key"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(b, []byte{1, 2}) || hexValue.String() != "vault://secret/data/app#key" {
+		t.Fatalf("got: %X from %q, expected 0102", b, hexValue.String())
+	}
+
+	if err := hexValue.Set("vault://secret/data/app#missing"); err == nil {
+		t.Fatalf("expected failure with missing reference")
+	}
+	if err := hexValue.Set("unknown://secret"); err == nil || !strings.Contains(err.Error(), "no resolver registered for scheme unknown") {
+		t.Fatalf("got: %v, expected unregistered scheme failure", err)
+	}
+}
+
+func TestResolveTimeout(t *testing.T) {
+	RegisterResolver("slow", ResolverFunc(func(ctx context.Context, ref string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	defer RegisterResolver("slow", nil)
+
+	previous := ResolveTimeout
+	ResolveTimeout = time.Millisecond
+	defer func() { ResolveTimeout = previous }()
+
+	var b []byte
+	if err := BytesHex(&b, nil).Set("slow:ref"); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Fatalf("got: %v, expected deadline exceeded", err)
+	}
+}
+
+func TestCachedResolver(t *testing.T) {
+	calls := 0
+	r := CachedResolver(ResolverFunc(func(ctx context.Context, ref string) ([]byte, error) {
+		calls++
+		return []byte(ref), nil
+	}), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		data, err := r.Resolve(context.Background(), "ref")
+		if err != nil || string(data) != "ref" {
+			t.Fatalf("got: %q, %v, expected %q", data, err, "ref")
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("got: %d, expected %d", calls, 1)
+	}
+}
+
+func TestFileResolver(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "value"), []byte("content"), 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	data, err := FileResolver{Dir: dir}.Resolve(context.Background(), "value")
+	if err != nil || string(data) != "content" {
+		t.Fatalf("got: %q, %v, expected %q", data, err, "content")
+	}
+}
+
+func TestReloadStdin(t *testing.T) {
+	previous := stdin
+	stdin = strings.NewReader("0102")
+	defer func() { stdin = previous }()
+
+	var b []byte
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(BytesHex(&b, nil), "key", "key")
+	if err := fs.Parse([]string{"-key", "-"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := Reload(fs); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(b, []byte{1, 2}) {
+		t.Fatalf("got: %X, expected standard input not to be read again", b)
+	}
+}
@@ -0,0 +1,148 @@
+package flagvars
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// tlsVersions lists the TLS versions by name, oldest first.
+var tlsVersions = []struct {
+	name    string
+	version uint16
+}{
+	{"1.0", tls.VersionTLS10},
+	{"1.1", tls.VersionTLS11},
+	{"1.2", tls.VersionTLS12},
+	{"1.3", tls.VersionTLS13},
+}
+
+// TLSVersionNames returns the names TLSVersion accepts, oldest first.
+func TLSVersionNames() []string {
+	names := make([]string, 0, len(tlsVersions))
+	for _, v := range tlsVersions {
+		names = append(names, v.name)
+	}
+	return names
+}
+
+// CipherSuiteNames returns the names of the cipher suites CipherSuites
+// accepts without reservation, as listed by tls.CipherSuites.
+func CipherSuiteNames() []string {
+	var names []string
+	for _, c := range tls.CipherSuites() {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// tlsVersionValue adapts a TLS version, as used by tls.Config.MinVersion, for
+// use as a flag. Value of flag is one of TLSVersionNames, optionally
+// prefixed by TLS.
+type tlsVersionValue struct {
+	dst *uint16
+}
+
+// String implements flag.Value.String.
+func (v tlsVersionValue) String() string {
+	if v.dst == nil {
+		return ""
+	}
+	for _, tv := range tlsVersions {
+		if tv.version == *v.dst {
+			return tv.name
+		}
+	}
+	return ""
+}
+
+// Set implements flag.Value.Set.
+func (v *tlsVersionValue) Set(value string) error {
+	name := strings.TrimSpace(value)
+	if len(name) > 3 && strings.EqualFold(name[:3], "tls") {
+		name = strings.TrimSpace(name[3:])
+	}
+	for _, tv := range tlsVersions {
+		if tv.name == name {
+			*v.dst = tv.version
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown TLS version %q, expected one of %s", value, strings.Join(TLSVersionNames(), ", "))
+}
+
+// Type implements flag.Value.Type.
+func (*tlsVersionValue) Type() string {
+	return TypeTLSVersion
+}
+
+// Get implements flag.Getter. It returns a uint16.
+func (v tlsVersionValue) Get() interface{} {
+	return *v.dst
+}
+
+// TLSVersion creates and returns a new flag.Value compliant TLS version
+// parser.
+func TLSVersion(p *uint16) flag.Value {
+	return &tlsVersionValue{dst: p}
+}
+
+// cipherSuitesValue adapts a list of cipher suites, as used by
+// tls.Config.CipherSuites, for use as a flag. Value of flag is a comma
+// separated list of cipher suite names.
+type cipherSuitesValue struct {
+	dst *[]uint16
+}
+
+// String implements flag.Value.String.
+func (v cipherSuitesValue) String() string {
+	if v.dst == nil {
+		return ""
+	}
+	names := make([]string, 0, len(*v.dst))
+	for _, id := range *v.dst {
+		names = append(names, tls.CipherSuiteName(id))
+	}
+	return strings.Join(names, ",")
+}
+
+// Set implements flag.Value.Set. Insecure cipher suites, as listed by
+// tls.InsecureCipherSuites, are accepted too.
+func (v *cipherSuitesValue) Set(value string) error {
+	byName := make(map[string]uint16)
+	for _, c := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		byName[c.Name] = c.ID
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	*v.dst = ids
+	return nil
+}
+
+// Type implements flag.Value.Type.
+func (*cipherSuitesValue) Type() string {
+	return TypeCipherSuites
+}
+
+// Get implements flag.Getter. It returns a []uint16.
+func (v cipherSuitesValue) Get() interface{} {
+	return *v.dst
+}
+
+// CipherSuites creates and returns a new flag.Value compliant cipher suites
+// parser.
+func CipherSuites(p *[]uint16) flag.Value {
+	return &cipherSuitesValue{dst: p}
+}
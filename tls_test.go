@@ -0,0 +1,73 @@
+package flagvars
+
+import (
+	"crypto/tls"
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestTLSVersion(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected uint16
+	}{
+		{"1.2", tls.VersionTLS12},
+		{"TLS1.3", tls.VersionTLS13},
+		{"tls 1.0", tls.VersionTLS10},
+	}
+	for _, tc := range testCases {
+		var version uint16
+		v := TLSVersion(&version)
+		if err := v.Set(tc.value); err != nil {
+			t.Fatalf("expected success, got %q", err)
+		}
+		if version != tc.expected {
+			t.Fatalf("got: %x, expected %x", version, tc.expected)
+		}
+	}
+
+	var version uint16
+	v := TLSVersion(&version)
+	if err := v.Set("1.4"); err == nil {
+		t.Fatalf("expected failure with unknown version")
+	}
+	version = tls.VersionTLS12
+	if v.String() != "1.2" {
+		t.Fatalf("got: %q, expected %q", v.String(), "1.2")
+	}
+}
+
+func TestCipherSuites(t *testing.T) {
+	var suites []uint16
+	v := CipherSuites(&suites)
+	value := "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_RSA_WITH_RC4_128_SHA"
+	if err := v.Set(value); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	expected := []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_RC4_128_SHA}
+	if !reflect.DeepEqual(suites, expected) {
+		t.Fatalf("got: %v, expected %v", suites, expected)
+	}
+	if v.String() != "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_RSA_WITH_RC4_128_SHA" {
+		t.Fatalf("got: %q", v.String())
+	}
+	if err := v.Set("TLS_UNKNOWN"); err == nil {
+		t.Fatalf("expected failure with unknown cipher suite")
+	}
+	if len(CipherSuiteNames()) == 0 || len(TLSVersionNames()) != 4 {
+		t.Fatalf("expected cipher suite and version names")
+	}
+}
+
+func TestTLSVersionVar(t *testing.T) {
+	var version uint16
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Var(TLSVersion(&version), "tls-min-version", "minimum TLS version")
+}
+
+func TestCipherSuitesVar(t *testing.T) {
+	var suites []uint16
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Var(CipherSuites(&suites), "tls-cipher-suites", "TLS cipher suites")
+}
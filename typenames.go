@@ -0,0 +1,42 @@
+package flagvars
+
+// Type names returned by the Type method of the values of this package, which
+// spf13/pflag shows in usage messages and pflagvars relies on to pick shell
+// completions. They are lowerCamelCase and will not change.
+const (
+	TypeBytes                  = "bytes"
+	TypeBytesHex               = "bytesHex"
+	TypeBytesHexFormatted      = "bytesHexFormatted"
+	TypeBytesBase64            = "bytesBase64"
+	TypeBytesArray             = "bytesArray"
+	TypeBytesFile              = "bytesFile"
+	TypeBytesFileLazy          = "bytesFileLazy"
+	TypeBytesEncoded           = "bytesEncoded"
+	TypeBytesBase64URL         = "bytesBase64URL"
+	TypeBytesBase64RawURL      = "bytesBase64RawURL"
+	TypeBytesBase64Raw         = "bytesBase64Raw"
+	TypeBytesBase32            = "bytesBase32"
+	TypeBytesBase32Raw         = "bytesBase32Raw"
+	TypeBytesBase32Hex         = "bytesBase32Hex"
+	TypeBytesBase32HexRaw      = "bytesBase32HexRaw"
+	TypeBytesBase58            = "bytesBase58"
+	TypeBytesAscii85           = "bytesAscii85"
+	TypeSecretBytesHex         = "secretBytesHex"
+	TypeSecretBytesBase64      = "secretBytesBase64"
+	TypeSecretBytesFile        = "secretBytesFile"
+	TypeCertificate            = "certificate"
+	TypeCertificates           = "certificates"
+	TypeCertPool               = "certPool"
+	TypeTLSCertificate         = "tlsCertificate"
+	TypeTLSKeyPair             = "tlsKeyPair"
+	TypeTrustBundle            = "trustBundle"
+	TypeCertificateAuthority   = "certificateAuthority"
+	TypeKubernetesTLSSecretDir = "kubernetesTLSSecretDir"
+	TypePrivateKey             = "privateKey"
+	TypeRSAPrivateKey          = "rsaPrivateKey"
+	TypeRSAPublicKey           = "rsaPublicKey"
+	TypeECDSAPrivateKey        = "ecdsaPrivateKey"
+	TypeECDSAPublicKey         = "ecdsaPublicKey"
+	TypeTLSVersion             = "tlsVersion"
+	TypeCipherSuites           = "cipherSuites"
+)
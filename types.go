@@ -0,0 +1,227 @@
+package flagvars
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrSecretMarshal is returned when marshaling a value holding private key
+// material, which is never written back out.
+var ErrSecretMarshal = errors.New("refusing to marshal private key material")
+
+// The types below can be used as fields of configuration structs decoded from
+// JSON, YAML or any format relying on encoding.TextUnmarshaler, and as flags
+// through their address, parsing values as the matching flag.Value does.
+// Empty text leaves them unset.
+
+// PEMCertificate is an x509.Certificate read from PEM, as Certificate does.
+type PEMCertificate struct {
+	x509.Certificate
+}
+
+// String implements flag.Value.String.
+func (c PEMCertificate) String() string {
+	return certificateValue{dst: &c.Certificate}.String()
+}
+
+// Set implements flag.Value.Set.
+func (c *PEMCertificate) Set(value string) error {
+	return (&certificateValue{dst: &c.Certificate}).Set(value)
+}
+
+// Type implements flag.Value.Type.
+func (*PEMCertificate) Type() string {
+	return TypeCertificate
+}
+
+// Get implements flag.Getter. It returns a *x509.Certificate.
+func (c *PEMCertificate) Get() interface{} {
+	return &c.Certificate
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c PEMCertificate) MarshalText() ([]byte, error) {
+	if c.Raw == nil {
+		return []byte{}, nil
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw}), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *PEMCertificate) UnmarshalText(text []byte) error {
+	return unmarshalText(c.Set, text)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c PEMCertificate) MarshalJSON() ([]byte, error) {
+	return marshalJSON(c.MarshalText)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *PEMCertificate) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(c.UnmarshalText, data)
+}
+
+// PEMPrivateKey is a PKCS #1, SEC 1 or PKCS #8 private key read from PEM, as
+// PrivateKey does. It refuses to be marshaled, returning ErrSecretMarshal.
+type PEMPrivateKey struct {
+	crypto.Signer
+}
+
+// String implements flag.Value.String.
+func (k PEMPrivateKey) String() string {
+	return privateKeyValue{dst: &k.Signer}.String()
+}
+
+// Set implements flag.Value.Set.
+func (k *PEMPrivateKey) Set(value string) error {
+	return (&privateKeyValue{dst: &k.Signer}).Set(value)
+}
+
+// Type implements flag.Value.Type.
+func (*PEMPrivateKey) Type() string {
+	return TypePrivateKey
+}
+
+// Get implements flag.Getter. It returns a crypto.Signer.
+func (k PEMPrivateKey) Get() interface{} {
+	return k.Signer
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (PEMPrivateKey) MarshalText() ([]byte, error) {
+	return nil, ErrSecretMarshal
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *PEMPrivateKey) UnmarshalText(text []byte) error {
+	return unmarshalText(k.Set, text)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (PEMPrivateKey) MarshalJSON() ([]byte, error) {
+	return nil, ErrSecretMarshal
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (k *PEMPrivateKey) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(k.UnmarshalText, data)
+}
+
+// HexBytes is a []byte read from HEX, as BytesHex does.
+type HexBytes []byte
+
+// String implements flag.Value.String.
+func (b HexBytes) String() string {
+	return fmt.Sprintf("%X", []byte(b))
+}
+
+// Set implements flag.Value.Set.
+func (b *HexBytes) Set(value string) error {
+	return (&bytesHexValue{dst: (*[]byte)(b)}).Set(value)
+}
+
+// Type implements flag.Value.Type.
+func (*HexBytes) Type() string {
+	return TypeBytesHex
+}
+
+// Get implements flag.Getter. It returns a []byte.
+func (b HexBytes) Get() interface{} {
+	return []byte(b)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	return unmarshalText(b.Set, text)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b HexBytes) MarshalJSON() ([]byte, error) {
+	return marshalJSON(b.MarshalText)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *HexBytes) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(b.UnmarshalText, data)
+}
+
+// Base64Bytes is a []byte read from standard Base64, as BytesBase64 does.
+type Base64Bytes []byte
+
+// String implements flag.Value.String.
+func (b Base64Bytes) String() string {
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// Set implements flag.Value.Set.
+func (b *Base64Bytes) Set(value string) error {
+	return (&bytesBase64Value{dst: (*[]byte)(b)}).Set(value)
+}
+
+// Type implements flag.Value.Type.
+func (*Base64Bytes) Type() string {
+	return TypeBytesBase64
+}
+
+// Get implements flag.Getter. It returns a []byte.
+func (b Base64Bytes) Get() interface{} {
+	return []byte(b)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b Base64Bytes) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *Base64Bytes) UnmarshalText(text []byte) error {
+	return unmarshalText(b.Set, text)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b Base64Bytes) MarshalJSON() ([]byte, error) {
+	return marshalJSON(b.MarshalText)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Base64Bytes) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(b.UnmarshalText, data)
+}
+
+// unmarshalText passes text to set unless it is empty.
+func unmarshalText(set func(string) error, text []byte) error {
+	if len(text) == 0 {
+		return nil
+	}
+	return set(string(text))
+}
+
+// marshalJSON encodes the text returned by marshalText as a JSON string.
+func marshalJSON(marshalText func() ([]byte, error)) ([]byte, error) {
+	text, err := marshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// unmarshalJSON decodes a JSON string, or null, and passes it to
+// unmarshalText.
+func unmarshalJSON(unmarshalText func([]byte) error, data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return unmarshalText([]byte(s))
+}
@@ -0,0 +1,98 @@
+package flagvars
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestTypesJSON(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(generated.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+
+	var cfg struct {
+		Cert  PEMCertificate `json:"cert"`
+		Key   PEMPrivateKey  `json:"key"`
+		Hex   HexBytes       `json:"hex"`
+		B64   Base64Bytes    `json:"b64"`
+		Empty HexBytes       `json:"empty"`
+	}
+	data, _ := json.Marshal(map[string]string{"cert": certPEM, "key": keyPEM, "hex": "0102", "b64": "AwQ=", "empty": ""})
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !cfg.Cert.Equal(generated.Leaf) {
+		t.Fatalf("got: %v, expected %v", cfg.Cert.Subject, generated.Leaf.Subject)
+	}
+	if !publicKeysEqual(cfg.Key.Public(), generated.Leaf.PublicKey) {
+		t.Fatalf("expected private key to match certificate")
+	}
+	if !bytes.Equal(cfg.Hex, []byte{1, 2}) || !bytes.Equal(cfg.B64, []byte{3, 4}) || cfg.Empty != nil {
+		t.Fatalf("got: %X, %X, %X, expected 0102, 0304, nil", cfg.Hex, cfg.B64, cfg.Empty)
+	}
+
+	if _, err := json.Marshal(cfg); !errors.Is(err, ErrSecretMarshal) {
+		t.Fatalf("got: %v, expected %v", err, ErrSecretMarshal)
+	}
+	if s := cfg.Key.String(); strings.Contains(s, "PRIVATE KEY") {
+		t.Fatalf("expected private key not to be rendered, got %q", s)
+	}
+
+	public := struct {
+		Cert PEMCertificate `json:"cert"`
+		Hex  HexBytes       `json:"hex"`
+		B64  Base64Bytes    `json:"b64"`
+	}{cfg.Cert, cfg.Hex, cfg.B64}
+	data, err = json.Marshal(public)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	expected := map[string]string{"cert": certPEM, "hex": "0102", "b64": "AwQ="}
+	for k, v := range expected {
+		if decoded[k] != v {
+			t.Fatalf("got: %q, expected %q", decoded[k], v)
+		}
+	}
+}
+
+func TestTypesUnmarshalFailure(t *testing.T) {
+	var cert PEMCertificate
+	if err := json.Unmarshal([]byte(`"not a certificate"`), &cert); err == nil {
+		t.Fatalf("expected failure with invalid certificate")
+	}
+	var b HexBytes
+	if err := b.UnmarshalText([]byte("zz")); err == nil {
+		t.Fatalf("expected failure with invalid hex")
+	}
+}
+
+func TestTypesVar(t *testing.T) {
+	var (
+		cert PEMCertificate
+		key  PEMPrivateKey
+		hex  HexBytes
+		b64  Base64Bytes
+	)
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Var(&cert, "cert", "certificate")
+	fs.Var(&key, "key", "private key")
+	fs.Var(&hex, "hex", "hex bytes")
+	fs.Var(&b64, "b64", "base64 bytes")
+}
@@ -0,0 +1,140 @@
+package flagvars
+
+import (
+	"crypto"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// publicKeyHolder is implemented by values holding a key or a certificate
+// whose public key can be compared with others. publicKey returns nil while
+// the value has not been set.
+type publicKeyHolder interface {
+	publicKey() crypto.PublicKey
+}
+
+// defaultErrorer is implemented by values whose default may have failed to
+// parse. defaultError returns nil otherwise.
+type defaultErrorer interface {
+	defaultError() error
+}
+
+// validations holds the checks declared for each flag set.
+var validations = struct {
+	sync.Mutex
+	m map[*flag.FlagSet][]func(*flag.FlagSet) error
+}{m: make(map[*flag.FlagSet][]func(*flag.FlagSet) error)}
+
+// declare registers check to be run by Validate on fs.
+func declare(fs *flag.FlagSet, check func(*flag.FlagSet) error) {
+	validations.Lock()
+	defer validations.Unlock()
+	validations.m[fs] = append(validations.m[fs], check)
+}
+
+// declareDefault is like declare for check setting the default of a flag,
+// which runs ahead of the checks which may depend on it.
+func declareDefault(fs *flag.FlagSet, check func(*flag.FlagSet) error) {
+	validations.Lock()
+	defer validations.Unlock()
+	validations.m[fs] = append([]func(*flag.FlagSet) error{check}, validations.m[fs]...)
+}
+
+// Release drops what this package keeps about fs: the checks declared for
+// it, such as MatchKeyPair and the defaults of the Var helpers, and the
+// record of the flags BindEnv set, which Origins relies on. They are
+// otherwise kept, and so is fs, for the lifetime of the process, so flag sets
+// which are created repeatedly, such as one per test or per request, should
+// be released once done with.
+func Release(fs *flag.FlagSet) {
+	fs = commandLine(fs)
+
+	validations.Lock()
+	delete(validations.m, fs)
+	validations.Unlock()
+
+	envBindings.Lock()
+	delete(envBindings.m, fs)
+	envBindings.Unlock()
+}
+
+// MatchKeyPair declares that the private key held by the keyFlag flag of fs
+// must match the public key or certificate held by the pubFlag flag. The
+// check runs through Validate once fs has been parsed and is skipped while
+// either flag has no value.
+func MatchKeyPair(fs *flag.FlagSet, keyFlag, pubFlag string) {
+	declare(fs, func(fs *flag.FlagSet) error {
+		priv, err := lookupPublicKey(fs, keyFlag)
+		if err != nil {
+			return err
+		}
+		pub, err := lookupPublicKey(fs, pubFlag)
+		if err != nil {
+			return err
+		}
+		if priv == nil || pub == nil || publicKeysEqual(priv, pub) {
+			return nil
+		}
+		return fmt.Errorf("flag -%s (sha256=%s) does not match flag -%s (sha256=%s)",
+			keyFlag, publicKeyFingerprint(priv),
+			pubFlag, publicKeyFingerprint(pub))
+	})
+}
+
+// lookupPublicKey returns the public key held by the name flag of fs.
+func lookupPublicKey(fs *flag.FlagSet, name string) (crypto.PublicKey, error) {
+	f := fs.Lookup(name)
+	if f == nil {
+		return nil, fmt.Errorf("flag -%s is not defined", name)
+	}
+	h, ok := f.Value.(publicKeyHolder)
+	if !ok {
+		return nil, fmt.Errorf("flag -%s does not hold a key or certificate", name)
+	}
+	return h.publicKey(), nil
+}
+
+// Validate sets the deferred defaults of flags left unset, such as a file:
+// or self-signed: default, runs every check declared for fs, such as
+// MatchKeyPair, reports the defaults of flags left unset which failed to
+// parse, such as a missing BytesFile default, and returns all failures joined
+// in a single error. It is meant to be called right after fs.Parse.
+func Validate(fs *flag.FlagSet) error {
+	validations.Lock()
+	checks := validations.m[fs]
+	validations.Unlock()
+
+	var msgs []string
+	for _, check := range checks {
+		if err := check(fs); err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		d, ok := f.Value.(defaultErrorer)
+		if !ok || isSet(fs, f.Name) {
+			return
+		}
+		if err := d.defaultError(); err != nil {
+			msgs = append(msgs, fmt.Sprintf("invalid default value for flag -%s: %v", f.Name, err))
+		}
+	})
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// Parse parses args with fs, or flag.CommandLine if fs is nil, and then calls
+// Validate, so that invalid defaults and failed checks are reported along
+// with command line errors.
+func Parse(fs *flag.FlagSet, args []string) error {
+	fs = commandLine(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return Validate(fs)
+}
@@ -0,0 +1,112 @@
+package flagvars
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestMatchKeyPair(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	other, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	keyDer, _ := x509.MarshalPKCS8PrivateKey(generated.PrivateKey)
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+	otherPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: other.Leaf.Raw}))
+
+	var (
+		key  crypto.Signer
+		cert x509.Certificate
+	)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(PrivateKey(&key), "tls-key", "private key")
+	fs.Var(Certificate(&cert), "tls-cert", "certificate")
+	MatchKeyPair(fs, "tls-key", "tls-cert")
+
+	if err := Validate(fs); err != nil {
+		t.Fatalf("expected unset flags to be skipped, got %q", err)
+	}
+
+	if err := fs.Parse([]string{"-tls-key", keyPEM, "-tls-cert", certPEM}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := Validate(fs); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	if err := fs.Parse([]string{"-tls-cert", otherPEM}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	err = Validate(fs)
+	if err == nil {
+		t.Fatalf("expected failure with mismatching key pair")
+	}
+	for _, expected := range []string{
+		"-tls-key (sha256=" + publicKeyFingerprint(generated.Leaf.PublicKey) + ")",
+		"-tls-cert (sha256=" + publicKeyFingerprint(other.Leaf.PublicKey) + ")",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got: %q, expected to contain %q", err, expected)
+		}
+	}
+}
+
+func TestMatchKeyPairUndefined(t *testing.T) {
+	var tlsCert tls.Certificate
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(TLSCertificate(&tlsCert), "tls-cert", "TLS certificate")
+	fs.String("name", "", "name")
+
+	MatchKeyPair(fs, "tls-cert", "missing")
+	MatchKeyPair(fs, "tls-cert", "name")
+
+	err := Validate(fs)
+	if err == nil {
+		t.Fatalf("expected failure with undefined flags")
+	}
+	if !strings.Contains(err.Error(), "-missing is not defined") || !strings.Contains(err.Error(), "-name does not hold") {
+		t.Fatalf("got: %q", err)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	t.Setenv("APP_KEY", "0102")
+
+	var (
+		cert x509.Certificate
+		key  []byte
+	)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	CertificateVar(fs, &cert, "cert", "invalid", "certificate")
+	BytesHexVar(fs, &key, "key", nil, "key")
+	if err := Validate(fs); err == nil {
+		t.Fatalf("expected failure with invalid default")
+	}
+	if err := BindEnv(fs, "APP"); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	Release(fs)
+	validations.Lock()
+	_, declared := validations.m[fs]
+	validations.Unlock()
+	envBindings.Lock()
+	_, bound := envBindings.m[fs]
+	envBindings.Unlock()
+	if declared || bound {
+		t.Fatalf("expected flag set to be released")
+	}
+	if err := Validate(fs); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+}
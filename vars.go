@@ -0,0 +1,629 @@
+package flagvars
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// The XxxVar functions below define a flag in fs, or flag.CommandLine when fs
+// is nil, storing its value in the variable p points to, and the Xxx
+// functions return the address of a new variable instead, as the flag
+// package does.
+//
+// Inline default values are parsed at once, as they would be on the command
+// line. Defaults referring to their content through file:, env: and the
+// other sources, generating it, as self-signed: does, or naming a file, are
+// only resolved by Validate, and Parse, when the flag has not been set, so
+// that nothing is fetched, run or written for a default which is overridden.
+// Until then the variable keeps its zero value. A default which fails to
+// parse does not prevent the flag from being defined: the failure is
+// reported by Validate unless the flag is set.
+
+// commandLine returns fs, or flag.CommandLine if fs is nil.
+func commandLine(fs *flag.FlagSet) *flag.FlagSet {
+	if fs == nil {
+		return flag.CommandLine
+	}
+	return fs
+}
+
+// define sets v to value, unless empty, and defines the name flag of fs with
+// it. Values which are not inline are deferred as by defineDeferred.
+func define(fs *flag.FlagSet, v flag.Value, name, value, usage string) {
+	if strings.HasPrefix(value, selfSignedPrefix) ||
+		hasSourcePrefix(value) && !strings.HasPrefix(value, "literal:") {
+		defineDeferred(fs, v, name, value, usage)
+		return
+	}
+	fs = commandLine(fs)
+	if value != "" {
+		if err := v.Set(value); err != nil {
+			declare(fs, func(fs *flag.FlagSet) error {
+				if isSet(fs, name) {
+					return nil
+				}
+				return fmt.Errorf("invalid default value for flag -%s: %v", name, err)
+			})
+		}
+	}
+	fs.Var(v, name, usage)
+}
+
+// defineDeferred defines the name flag of fs with v, showing value as its
+// default. Unless empty, value is set by Validate, once, if the flag has not
+// been set by then.
+func defineDeferred(fs *flag.FlagSet, v flag.Value, name, value, usage string) {
+	fs = commandLine(fs)
+	fs.Var(v, name, usage)
+	if value == "" {
+		return
+	}
+	fs.Lookup(name).DefValue = value
+	applied := false
+	declareDefault(fs, func(fs *flag.FlagSet) error {
+		if applied || isSet(fs, name) {
+			return nil
+		}
+		if err := v.Set(value); err != nil {
+			return fmt.Errorf("invalid default value for flag -%s: %v", name, err)
+		}
+		applied = true
+		return nil
+	})
+}
+
+// CertificateVar defines an x509.Certificate flag with the specified name,
+// default value and usage string. See Certificate.
+func CertificateVar(fs *flag.FlagSet, p *x509.Certificate, name, value, usage string) {
+	define(fs, Certificate(p), name, value, usage)
+}
+
+// CertificateFlag defines an x509.Certificate flag with the specified name,
+// default value and usage string, and returns the address of the variable
+// storing its value.
+func CertificateFlag(fs *flag.FlagSet, name, value, usage string) *x509.Certificate {
+	p := new(x509.Certificate)
+	CertificateVar(fs, p, name, value, usage)
+	return p
+}
+
+// CertificatesVar defines an []*x509.Certificate flag with the specified
+// name, default value and usage string. See Certificates.
+func CertificatesVar(fs *flag.FlagSet, p *[]*x509.Certificate, name, value, usage string) {
+	define(fs, Certificates(p), name, value, usage)
+}
+
+// CertificatesFlag defines an []*x509.Certificate flag with the specified
+// name, default value and usage string, and returns the address of the
+// variable storing its value.
+func CertificatesFlag(fs *flag.FlagSet, name, value, usage string) *[]*x509.Certificate {
+	p := new([]*x509.Certificate)
+	CertificatesVar(fs, p, name, value, usage)
+	return p
+}
+
+// CertPoolVar defines an x509.CertPool flag with the specified name, default
+// value and usage string. See CertPool.
+func CertPoolVar(fs *flag.FlagSet, p *x509.CertPool, name, value, usage string) {
+	define(fs, CertPool(p), name, value, usage)
+}
+
+// CertPoolFlag defines an x509.CertPool flag with the specified name, default
+// value and usage string, and returns the address of the pool storing its
+// value.
+func CertPoolFlag(fs *flag.FlagSet, name, value, usage string) *x509.CertPool {
+	p := x509.NewCertPool()
+	CertPoolVar(fs, p, name, value, usage)
+	return p
+}
+
+// TLSCertificateVar defines a tls.Certificate flag with the specified name,
+// default value and usage string. See TLSCertificate.
+func TLSCertificateVar(fs *flag.FlagSet, p *tls.Certificate, name, value, usage string) {
+	define(fs, TLSCertificate(p), name, value, usage)
+}
+
+// TLSCertificateFlag defines a tls.Certificate flag with the specified name,
+// default value and usage string, and returns the address of the variable
+// storing its value.
+func TLSCertificateFlag(fs *flag.FlagSet, name, value, usage string) *tls.Certificate {
+	p := new(tls.Certificate)
+	TLSCertificateVar(fs, p, name, value, usage)
+	return p
+}
+
+// TLSCertificateSelfSignedVar defines a tls.Certificate flag accepting
+// self-signed:host,... with the specified name, default value and usage
+// string. See TLSCertificateSelfSigned.
+func TLSCertificateSelfSignedVar(fs *flag.FlagSet, p *tls.Certificate, opts SelfSignedOptions, name, value, usage string) {
+	define(fs, TLSCertificateSelfSigned(p, opts), name, value, usage)
+}
+
+// TLSCertificateSelfSignedFlag defines a tls.Certificate flag accepting
+// self-signed:host,... with the specified name, default value and usage
+// string, and returns the address of the variable storing its value.
+func TLSCertificateSelfSignedFlag(fs *flag.FlagSet, opts SelfSignedOptions, name, value, usage string) *tls.Certificate {
+	p := new(tls.Certificate)
+	TLSCertificateSelfSignedVar(fs, p, opts, name, value, usage)
+	return p
+}
+
+// CertificateAuthorityVar defines a CA flag with the specified name, default
+// value and usage string. See CertificateAuthority.
+func CertificateAuthorityVar(fs *flag.FlagSet, ca *CA, name, value, usage string) {
+	define(fs, CertificateAuthority(ca), name, value, usage)
+}
+
+// CertificateAuthorityFlag defines a CA flag with the specified name, default
+// value and usage string, and returns the address of the CA.
+func CertificateAuthorityFlag(fs *flag.FlagSet, name, value, usage string) *CA {
+	ca := new(CA)
+	CertificateAuthorityVar(fs, ca, name, value, usage)
+	return ca
+}
+
+// KubernetesTLSSecretDirVar defines a KubernetesTLSSecret flag with the
+// specified name, default directory and usage string. See
+// KubernetesTLSSecretDir.
+func KubernetesTLSSecretDirVar(fs *flag.FlagSet, s *KubernetesTLSSecret, name, value, usage string) {
+	defineDeferred(fs, KubernetesTLSSecretDir(s), name, value, usage)
+}
+
+// KubernetesTLSSecretDirFlag defines a KubernetesTLSSecret flag with the
+// specified name, default directory and usage string, and returns the
+// address of the secret.
+func KubernetesTLSSecretDirFlag(fs *flag.FlagSet, name, value, usage string) *KubernetesTLSSecret {
+	s := new(KubernetesTLSSecret)
+	KubernetesTLSSecretDirVar(fs, s, name, value, usage)
+	return s
+}
+
+// TrustBundleVar defines a TrustBundle flag with the specified name, default
+// value and usage string. See TrustBundlePEM.
+func TrustBundleVar(fs *flag.FlagSet, b *TrustBundle, name, value, usage string) {
+	define(fs, TrustBundlePEM(b), name, value, usage)
+}
+
+// TrustBundleFlag defines a TrustBundle flag with the specified name,
+// default value and usage string, and returns the address of the bundle.
+func TrustBundleFlag(fs *flag.FlagSet, name, value, usage string) *TrustBundle {
+	b := new(TrustBundle)
+	TrustBundleVar(fs, b, name, value, usage)
+	return b
+}
+
+// TLSKeyPairVar defines a TLSKeyPair flag with the specified name, default
+// value and usage string. See TLSKeyPairPEM.
+func TLSKeyPairVar(fs *flag.FlagSet, k *TLSKeyPair, name, value, usage string) {
+	define(fs, TLSKeyPairPEM(k), name, value, usage)
+}
+
+// TLSKeyPairFlag defines a TLSKeyPair flag with the specified name, default
+// value and usage string, and returns the address of the key pair.
+func TLSKeyPairFlag(fs *flag.FlagSet, name, value, usage string) *TLSKeyPair {
+	k := new(TLSKeyPair)
+	TLSKeyPairVar(fs, k, name, value, usage)
+	return k
+}
+
+// PrivateKeyVar defines a crypto.Signer flag with the specified name,
+// default value and usage string. See PrivateKey.
+func PrivateKeyVar(fs *flag.FlagSet, p *crypto.Signer, name, value, usage string) {
+	define(fs, PrivateKey(p), name, value, usage)
+}
+
+// PrivateKeyFlag defines a crypto.Signer flag with the specified name,
+// default value and usage string, and returns the address of the variable
+// storing its value.
+func PrivateKeyFlag(fs *flag.FlagSet, name, value, usage string) *crypto.Signer {
+	p := new(crypto.Signer)
+	PrivateKeyVar(fs, p, name, value, usage)
+	return p
+}
+
+// RSAPrivateKeyVar defines an rsa.PrivateKey flag with the specified name,
+// default value and usage string. See RSAPrivateKey.
+func RSAPrivateKeyVar(fs *flag.FlagSet, p *rsa.PrivateKey, name, value, usage string) {
+	define(fs, RSAPrivateKey(p), name, value, usage)
+}
+
+// RSAPrivateKeyFlag defines an rsa.PrivateKey flag with the specified name,
+// default value and usage string, and returns the address of the variable
+// storing its value.
+func RSAPrivateKeyFlag(fs *flag.FlagSet, name, value, usage string) *rsa.PrivateKey {
+	p := new(rsa.PrivateKey)
+	RSAPrivateKeyVar(fs, p, name, value, usage)
+	return p
+}
+
+// RSAPublicKeyVar defines an rsa.PublicKey flag with the specified name,
+// default value and usage string. See RSAPublicKey.
+func RSAPublicKeyVar(fs *flag.FlagSet, p *rsa.PublicKey, name, value, usage string) {
+	define(fs, RSAPublicKey(p), name, value, usage)
+}
+
+// RSAPublicKeyFlag defines an rsa.PublicKey flag with the specified name,
+// default value and usage string, and returns the address of the variable
+// storing its value.
+func RSAPublicKeyFlag(fs *flag.FlagSet, name, value, usage string) *rsa.PublicKey {
+	p := new(rsa.PublicKey)
+	RSAPublicKeyVar(fs, p, name, value, usage)
+	return p
+}
+
+// ECDSAPrivateKeyVar defines an ecdsa.PrivateKey flag with the specified
+// name, default value and usage string. See ECDSAPrivateKey.
+func ECDSAPrivateKeyVar(fs *flag.FlagSet, p *ecdsa.PrivateKey, name, value, usage string) {
+	define(fs, ECDSAPrivateKey(p), name, value, usage)
+}
+
+// ECDSAPrivateKeyFlag defines an ecdsa.PrivateKey flag with the specified
+// name, default value and usage string, and returns the address of the
+// variable storing its value.
+func ECDSAPrivateKeyFlag(fs *flag.FlagSet, name, value, usage string) *ecdsa.PrivateKey {
+	p := new(ecdsa.PrivateKey)
+	ECDSAPrivateKeyVar(fs, p, name, value, usage)
+	return p
+}
+
+// ECDSAPublicKeyVar defines an ecdsa.PublicKey flag with the specified name,
+// default value and usage string. See ECDSAPublicKey.
+func ECDSAPublicKeyVar(fs *flag.FlagSet, p *ecdsa.PublicKey, name, value, usage string) {
+	define(fs, ECDSAPublicKey(p), name, value, usage)
+}
+
+// ECDSAPublicKeyFlag defines an ecdsa.PublicKey flag with the specified name,
+// default value and usage string, and returns the address of the variable
+// storing its value.
+func ECDSAPublicKeyFlag(fs *flag.FlagSet, name, value, usage string) *ecdsa.PublicKey {
+	p := new(ecdsa.PublicKey)
+	ECDSAPublicKeyVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesHexVar defines a HEX encoded []byte flag with the specified name,
+// default value and usage string. See BytesHex.
+func BytesHexVar(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesHex(p, value), name, usage)
+}
+
+// BytesHexFlag defines a HEX encoded []byte flag with the specified name,
+// default value and usage string, and returns the address of the variable
+// storing its value.
+func BytesHexFlag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesHexVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesBase64Var defines a Base64 encoded []byte flag with the specified
+// name, default value and usage string. See BytesBase64.
+func BytesBase64Var(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesBase64(p, value), name, usage)
+}
+
+// BytesBase64Flag defines a Base64 encoded []byte flag with the specified
+// name, default value and usage string, and returns the address of the
+// variable storing its value.
+func BytesBase64Flag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesBase64Var(fs, p, name, value, usage)
+	return p
+}
+
+// BytesVar defines a []byte flag accepting encoding and source prefixes
+// with the specified name, default value and usage string. See Bytes.
+func BytesVar(fs *flag.FlagSet, p *[]byte, enc Encoding, name string, value []byte, usage string) {
+	commandLine(fs).Var(Bytes(p, value, enc), name, usage)
+}
+
+// BytesFlag defines a []byte flag accepting encoding and source prefixes
+// with the specified name, default value and usage string, and returns the
+// address of the variable storing its value.
+func BytesFlag(fs *flag.FlagSet, enc Encoding, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesVar(fs, p, enc, name, value, usage)
+	return p
+}
+
+// BytesEncodedVar defines a []byte flag decoded with enc with the specified
+// name, default value and usage string. See BytesEncoded.
+func BytesEncodedVar(fs *flag.FlagSet, p *[]byte, enc Encoding, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesEncoded(p, value, enc), name, usage)
+}
+
+// BytesEncodedFlag defines a []byte flag decoded with enc with the specified
+// name, default value and usage string, and returns the address of the
+// variable storing its value.
+func BytesEncodedFlag(fs *flag.FlagSet, enc Encoding, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesEncodedVar(fs, p, enc, name, value, usage)
+	return p
+}
+
+// BytesBase64URLVar defines a URL-safe base64 encoded []byte flag with the
+// specified name, default value and usage string. See BytesBase64URL.
+func BytesBase64URLVar(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesBase64URL(p, value), name, usage)
+}
+
+// BytesBase64URLFlag defines a URL-safe base64 encoded []byte flag with the
+// specified name, default value and usage string, and returns the address of
+// the variable storing its value.
+func BytesBase64URLFlag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesBase64URLVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesBase64RawURLVar defines an unpadded URL-safe base64 encoded []byte flag
+// with the specified name, default value and usage string. See
+// BytesBase64RawURL.
+func BytesBase64RawURLVar(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesBase64RawURL(p, value), name, usage)
+}
+
+// BytesBase64RawURLFlag defines an unpadded URL-safe base64 encoded []byte
+// flag with the specified name, default value and usage string, and returns
+// the address of the variable storing its value.
+func BytesBase64RawURLFlag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesBase64RawURLVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesBase64RawVar defines an unpadded base64 encoded []byte flag with the
+// specified name, default value and usage string. See BytesBase64Raw.
+func BytesBase64RawVar(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesBase64Raw(p, value), name, usage)
+}
+
+// BytesBase64RawFlag defines an unpadded base64 encoded []byte flag with the
+// specified name, default value and usage string, and returns the address of
+// the variable storing its value.
+func BytesBase64RawFlag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesBase64RawVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesBase32Var defines a base32 encoded []byte flag with the specified name,
+// default value and usage string. See BytesBase32.
+func BytesBase32Var(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesBase32(p, value), name, usage)
+}
+
+// BytesBase32Flag defines a base32 encoded []byte flag with the specified
+// name, default value and usage string, and returns the address of the
+// variable storing its value.
+func BytesBase32Flag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesBase32Var(fs, p, name, value, usage)
+	return p
+}
+
+// BytesBase32RawVar defines an unpadded base32 encoded []byte flag with the
+// specified name, default value and usage string. See BytesBase32Raw.
+func BytesBase32RawVar(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesBase32Raw(p, value), name, usage)
+}
+
+// BytesBase32RawFlag defines an unpadded base32 encoded []byte flag with the
+// specified name, default value and usage string, and returns the address of
+// the variable storing its value.
+func BytesBase32RawFlag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesBase32RawVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesBase32HexVar defines an extended hex base32 encoded []byte flag with
+// the specified name, default value and usage string. See BytesBase32Hex.
+func BytesBase32HexVar(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesBase32Hex(p, value), name, usage)
+}
+
+// BytesBase32HexFlag defines an extended hex base32 encoded []byte flag with
+// the specified name, default value and usage string, and returns the address
+// of the variable storing its value.
+func BytesBase32HexFlag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesBase32HexVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesBase32HexRawVar defines an unpadded extended hex base32 encoded []byte
+// flag with the specified name, default value and usage string. See
+// BytesBase32HexRaw.
+func BytesBase32HexRawVar(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesBase32HexRaw(p, value), name, usage)
+}
+
+// BytesBase32HexRawFlag defines an unpadded extended hex base32 encoded []byte
+// flag with the specified name, default value and usage string, and returns
+// the address of the variable storing its value.
+func BytesBase32HexRawFlag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesBase32HexRawVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesHexFormattedVar defines a hex []byte flag accepting separated bytes
+// with the specified name, default value and usage string. See
+// BytesHexFormatted.
+func BytesHexFormattedVar(fs *flag.FlagSet, p *[]byte, style HexStyle, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesHexFormatted(p, value, style), name, usage)
+}
+
+// BytesHexFormattedFlag defines a hex []byte flag accepting separated bytes
+// with the specified name, default value and usage string, and returns the
+// address of the variable storing its value.
+func BytesHexFormattedFlag(fs *flag.FlagSet, style HexStyle, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesHexFormattedVar(fs, p, style, name, value, usage)
+	return p
+}
+
+// BytesBase58Var defines a base58 encoded []byte flag with the specified name,
+// default value and usage string. See BytesBase58.
+func BytesBase58Var(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesBase58(p, value), name, usage)
+}
+
+// BytesBase58Flag defines a base58 encoded []byte flag with the specified
+// name, default value and usage string, and returns the address of the
+// variable storing its value.
+func BytesBase58Flag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesBase58Var(fs, p, name, value, usage)
+	return p
+}
+
+// BytesAscii85Var defines an ascii85 encoded []byte flag with the specified
+// name, default value and usage string. See BytesAscii85.
+func BytesAscii85Var(fs *flag.FlagSet, p *[]byte, name string, value []byte, usage string) {
+	commandLine(fs).Var(BytesAscii85(p, value), name, usage)
+}
+
+// BytesAscii85Flag defines an ascii85 encoded []byte flag with the specified
+// name, default value and usage string, and returns the address of the
+// variable storing its value.
+func BytesAscii85Flag(fs *flag.FlagSet, name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	BytesAscii85Var(fs, p, name, value, usage)
+	return p
+}
+
+// BytesFileVar defines a []byte flag read from a file with the specified
+// name, default file and usage string. See BytesFile.
+func BytesFileVar(fs *flag.FlagSet, p *[]byte, name, value, usage string) {
+	defineDeferred(fs, &bytesFileValue{data: p}, name, value, usage)
+}
+
+// BytesFileFlag defines a []byte flag read from a file with the specified
+// name, default file and usage string, and returns the address of the
+// variable storing its value.
+func BytesFileFlag(fs *flag.FlagSet, name, value, usage string) *[]byte {
+	p := new([]byte)
+	BytesFileVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesFileWithOptionsVar defines a []byte flag read from a file according
+// to opts with the specified name, default file and usage string. See
+// BytesFileWithOptions.
+func BytesFileWithOptionsVar(fs *flag.FlagSet, p *[]byte, name, value string, opts BytesFileOptions, usage string) {
+	defineDeferred(fs, &bytesFileValue{data: p, opts: opts}, name, value, usage)
+}
+
+// BytesFileLazyVar defines a flag read from a file when first accessed with
+// the specified name, default file and usage string. See BytesFileLazy.
+func BytesFileLazyVar(fs *flag.FlagSet, f *LazyFile, name, value string, opts BytesFileOptions, usage string) {
+	commandLine(fs).Var(BytesFileLazy(f, value, opts), name, usage)
+}
+
+// BytesFileLazyFlag defines a flag read from a file when first accessed with
+// the specified name, default file and usage string, and returns the
+// address of the LazyFile storing its value.
+func BytesFileLazyFlag(fs *flag.FlagSet, name, value string, opts BytesFileOptions, usage string) *LazyFile {
+	f := new(LazyFile)
+	BytesFileLazyVar(fs, f, name, value, opts, usage)
+	return f
+}
+
+// SecretBytesHexVar defines a hex secret flag with the specified name and
+// usage string. See SecretBytesHex.
+func SecretBytesHexVar(fs *flag.FlagSet, s *Secret, name, usage string) {
+	commandLine(fs).Var(SecretBytesHex(s), name, usage)
+}
+
+// SecretBytesHexFlag defines a hex secret flag with the specified name and
+// usage string, and returns the address of the secret.
+func SecretBytesHexFlag(fs *flag.FlagSet, name, usage string) *Secret {
+	s := new(Secret)
+	SecretBytesHexVar(fs, s, name, usage)
+	return s
+}
+
+// SecretBytesBase64Var defines a base64 secret flag with the specified name
+// and usage string. See SecretBytesBase64.
+func SecretBytesBase64Var(fs *flag.FlagSet, s *Secret, name, usage string) {
+	commandLine(fs).Var(SecretBytesBase64(s), name, usage)
+}
+
+// SecretBytesBase64Flag defines a base64 secret flag with the specified name
+// and usage string, and returns the address of the secret.
+func SecretBytesBase64Flag(fs *flag.FlagSet, name, usage string) *Secret {
+	s := new(Secret)
+	SecretBytesBase64Var(fs, s, name, usage)
+	return s
+}
+
+// SecretBytesFileVar defines a secret flag read from a file with the
+// specified name, default file and usage string. See SecretBytesFile.
+func SecretBytesFileVar(fs *flag.FlagSet, s *Secret, name, value, usage string) {
+	defineDeferred(fs, SecretBytesFile(s), name, value, usage)
+}
+
+// SecretBytesFileFlag defines a secret flag read from a file with the
+// specified name, default file and usage string, and returns the address of
+// the secret.
+func SecretBytesFileFlag(fs *flag.FlagSet, name, value, usage string) *Secret {
+	s := new(Secret)
+	SecretBytesFileVar(fs, s, name, value, usage)
+	return s
+}
+
+// TLSVersionVar defines a TLS version flag with the specified name, default
+// value and usage string. See TLSVersion.
+func TLSVersionVar(fs *flag.FlagSet, p *uint16, name string, value uint16, usage string) {
+	*p = value
+	commandLine(fs).Var(TLSVersion(p), name, usage)
+}
+
+// TLSVersionFlag defines a TLS version flag with the specified name, default
+// value and usage string, and returns the address of the variable storing
+// its value.
+func TLSVersionFlag(fs *flag.FlagSet, name string, value uint16, usage string) *uint16 {
+	p := new(uint16)
+	TLSVersionVar(fs, p, name, value, usage)
+	return p
+}
+
+// CipherSuitesVar defines a cipher suites flag with the specified name,
+// default value and usage string. See CipherSuites.
+func CipherSuitesVar(fs *flag.FlagSet, p *[]uint16, name string, value []uint16, usage string) {
+	*p = value
+	commandLine(fs).Var(CipherSuites(p), name, usage)
+}
+
+// CipherSuitesFlag defines a cipher suites flag with the specified name,
+// default value and usage string, and returns the address of the variable
+// storing its value.
+func CipherSuitesFlag(fs *flag.FlagSet, name string, value []uint16, usage string) *[]uint16 {
+	p := new([]uint16)
+	CipherSuitesVar(fs, p, name, value, usage)
+	return p
+}
+
+// BytesArrayVar defines a byte array flag with the specified name and usage
+// string, the current content of the array being the default value. See
+// BytesArray.
+func BytesArrayVar[A any](fs *flag.FlagSet, p *A, enc Encoding, name, usage string) {
+	commandLine(fs).Var(BytesArray(p, enc), name, usage)
+}
+
+// BytesArrayFlag defines a byte array flag, such as a [32]byte one, with the
+// specified name and usage string, and returns the address of the array
+// storing its value.
+func BytesArrayFlag[A any](fs *flag.FlagSet, enc Encoding, name, usage string) *A {
+	p := new(A)
+	BytesArrayVar(fs, p, enc, name, usage)
+	return p
+}
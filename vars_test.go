@@ -0,0 +1,146 @@
+package flagvars
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVarDefaults(t *testing.T) {
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw})
+	path := filepath.Join(t.TempDir(), "ca.crt")
+	if err := ioutil.WriteFile(path, certPEM, 0600); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cert := CertificateFlag(fs, "tls-cert", "file:"+path, "certificate")
+	pool := CertPoolFlag(fs, "tls-ca", "file:"+path, "CA certificates")
+	key := BytesHexFlag(fs, "hmac-key", []byte{1, 2}, "HMAC key")
+	version := TLSVersionFlag(fs, "tls-min-version", tls.VersionTLS12, "minimum TLS version")
+	if err := fs.Parse([]string{"-tls-min-version", "1.3"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := Validate(fs); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+
+	if !cert.Equal(generated.Leaf) {
+		t.Fatalf("got: %v, expected %v", cert.Subject, generated.Leaf.Subject)
+	}
+	if _, err := generated.Leaf.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: pool}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if !bytes.Equal(*key, []byte{1, 2}) || *version != tls.VersionTLS13 {
+		t.Fatalf("got: %X, %x, expected 0102, TLS 1.3", *key, *version)
+	}
+	if f := fs.Lookup("tls-cert"); f.DefValue != "file:"+path {
+		t.Fatalf("got: %q, expected %q", f.DefValue, "file:"+path)
+	}
+}
+
+func TestVarInvalidDefault(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.crt")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	CertificateFlag(fs, "tls-cert", "file:"+missing, "certificate")
+	BytesFileFlag(fs, "data", missing, "data file")
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	err := Validate(fs)
+	if err == nil || !strings.Contains(err.Error(), "invalid default value for flag -tls-cert") ||
+		!strings.Contains(err.Error(), "invalid default value for flag -data") {
+		t.Fatalf("got: %v, expected invalid default values to be reported", err)
+	}
+
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Leaf.Raw}))
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	CertificateFlag(fs, "tls-cert", "file:"+missing, "certificate")
+	if err := fs.Parse([]string{"-tls-cert", certPEM}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if err := Validate(fs); err != nil {
+		t.Fatalf("expected overridden default to be ignored, got %q", err)
+	}
+}
+
+func TestVarDeferredDefaults(t *testing.T) {
+	var resolved int
+	RegisterResolver("test-deferred", ResolverFunc(func(context.Context, string) ([]byte, error) {
+		resolved++
+		return []byte("0102"), nil
+	}))
+	defer RegisterResolver("test-deferred", nil)
+
+	generated, err := generateSelfSigned([]string{"localhost"}, SelfSignedOptions{})
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: generated.Certificate[0]}))
+	keyDER, err := x509.MarshalPKCS8PrivateKey(generated.PrivateKey)
+	if err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	var secret Secret
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cert := TLSCertificateSelfSignedFlag(fs, SelfSignedOptions{CAFile: caFile}, "tls-cert", "self-signed:localhost", "certificate")
+	SecretBytesFileVar(fs, &secret, "secret", "test-deferred:key", "secret")
+	if f := fs.Lookup("tls-cert"); f.DefValue != "self-signed:localhost" {
+		t.Fatalf("got: %q, expected %q", f.DefValue, "self-signed:localhost")
+	}
+	if err := Parse(fs, []string{"-tls-cert", keyPEM + certPEM, "-secret", "literal:03"}); err != nil {
+		t.Fatalf("expected success, got %q", err)
+	}
+	if _, err := os.Stat(caFile); !os.IsNotExist(err) || resolved != 0 {
+		t.Fatalf("got: %v, %d resolutions, expected overridden defaults not to run", err, resolved)
+	}
+	if !bytes.Equal(cert.Certificate[0], generated.Certificate[0]) {
+		t.Fatalf("expected the certificate of the command line")
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	cert = TLSCertificateSelfSignedFlag(fs, SelfSignedOptions{CAFile: caFile}, "tls-cert", "self-signed:localhost", "certificate")
+	SecretBytesFileVar(fs, &secret, "secret", "test-deferred:key", "secret")
+	if len(cert.Certificate) != 0 {
+		t.Fatalf("expected default not to be generated when defined")
+	}
+	for i := 0; i < 2; i++ {
+		if err := Parse(fs, nil); err != nil {
+			t.Fatalf("expected success, got %q", err)
+		}
+	}
+	if _, err := os.Stat(caFile); err != nil || len(cert.Certificate) == 0 {
+		t.Fatalf("got: %v, expected default to be generated", err)
+	}
+	if resolved != 1 || string(secret.Bytes()) != "0102" {
+		t.Fatalf("got: %q after %d resolutions, expected 0102 after 1", secret.Bytes(), resolved)
+	}
+}
+
+func TestVarCommandLine(t *testing.T) {
+	pool := CertPoolFlag(nil, "test-var-command-line-ca", "", "CA certificates")
+	if pool == nil || flag.CommandLine.Lookup("test-var-command-line-ca") == nil {
+		t.Fatalf("expected flag to be defined in flag.CommandLine")
+	}
+}